@@ -5,6 +5,7 @@ import (
 	"github.com/zmsocc/generic"
 	"github.com/zmsocc/generic/internal/errs"
 	"golang.org/x/exp/rand"
+	"iter"
 )
 
 const (
@@ -17,11 +18,19 @@ const (
 type skipListNode[T any] struct {
 	val     T
 	forward []*skipListNode[T]
+	// span[i] 表示从当前结点沿着 forward[i] 走一步，在第 1 层上跨过了多少个结点
+	// forward[i] 为 nil 的时候没有意义，所以 head 在高于 level 的层上不需要维护
+	span []int
+	// backward 第 1 层上的前一个结点，第一个结点的 backward 为 nil，用于反向遍历
+	backward *skipListNode[T]
 }
 
+// SkipList 可索引的跳表，每个 forward 指针都记录了跨度，
+// 按照下标查找的时候累加跨度就可以逐层逼近，Get、Rank、DeleteAt 都是 O(log n) 的
 type SkipList[T any] struct {
 	head    *skipListNode[T]
-	level   int // SkipList为空时, level为1
+	tail    *skipListNode[T] // 最后一个结点，SkipList为空时为 nil
+	level   int              // SkipList为空时, level为1
 	compare generic.Comparator[T]
 	length  int
 }
@@ -30,6 +39,7 @@ func newSkipListNode[T any](val T, level int) *skipListNode[T] {
 	return &skipListNode[T]{
 		val:     val,
 		forward: make([]*skipListNode[T], level),
+		span:    make([]int, level),
 	}
 }
 
@@ -37,6 +47,7 @@ func NewSkipList[T any](compare generic.Comparator[T]) *SkipList[T] {
 	return &SkipList[T]{
 		head: &skipListNode[T]{
 			forward: make([]*skipListNode[T], MaxLever),
+			span:    make([]int, MaxLever),
 		},
 		level:   1,
 		compare: compare,
@@ -52,17 +63,71 @@ func NewSkipListOf[T any](src []T, compare generic.Comparator[T]) *SkipList[T] {
 	return sl
 }
 
-// Get 获取索引为 index 处的值
+// Get 获取索引为 index 处的值，时间复杂度为 O(log n)
 func (l *SkipList[T]) Get(index int) (T, error) {
 	if index < 0 || index >= l.length {
 		var zero T
 		return zero, errs.NewErrIndexOutOfRange(l.length-1, index)
 	}
+	return l.findByIndex(index).val, nil
+}
+
+// Rank 返回第一个和 val 相等的元素的下标，不存在的时候返回 -1，时间复杂度为 O(log n)
+// 例如排行榜按照分数从高到低排序，Rank 就是名次减一
+func (l *SkipList[T]) Rank(val T) int {
 	cur := l.head
-	for i := 0; i <= index; i++ {
-		cur = cur.forward[0]
+	rank := 0 // head 的排名为 0，第 i 个元素的排名为 i + 1
+	for i := l.level - 1; i >= 0; i-- {
+		for cur.forward[i] != nil && l.compare(cur.forward[i].val, val) < 0 {
+			rank += cur.span[i]
+			cur = cur.forward[i]
+		}
 	}
-	return cur.val, nil
+	cur = cur.forward[0]
+	if cur == nil || l.compare(cur.val, val) != 0 {
+		return -1
+	}
+	return rank
+}
+
+// DeleteAt 删除索引为 index 处的值并返回，时间复杂度为 O(log n)
+func (l *SkipList[T]) DeleteAt(index int) (T, error) {
+	if index < 0 || index >= l.length {
+		var zero T
+		return zero, errs.NewErrIndexOutOfRange(l.length-1, index)
+	}
+	update := make([]*skipListNode[T], MaxLever)
+	cur := l.head
+	traversed := 0
+	for i := l.level - 1; i >= 0; i-- {
+		// 停在第 index 个元素的前一个结点
+		for cur.forward[i] != nil && traversed+cur.span[i] <= index {
+			traversed += cur.span[i]
+			cur = cur.forward[i]
+		}
+		update[i] = cur
+	}
+	node := cur.forward[0]
+	l.deleteNode(node, update)
+	return node.val, nil
+}
+
+// Range 返回下标在 [from, to) 之间的元素，时间复杂度为 O(log n + to - from)
+func (l *SkipList[T]) Range(from, to int) ([]T, error) {
+	if from < 0 || from > l.length {
+		return nil, errs.NewErrIndexOutOfRange(l.length-1, from)
+	}
+	if to < from || to > l.length {
+		return nil, errs.NewErrIndexOutOfRange(l.length-1, to)
+	}
+	res := make([]T, 0, to-from)
+	if from == to {
+		return res, nil
+	}
+	for cur := l.findByIndex(from); len(res) < to-from; cur = cur.forward[0] {
+		res = append(res, cur.val)
+	}
+	return res, nil
 }
 
 // Search 查找 SkipList 中是否含有目标值 val，有返回 true，没有返回 false
@@ -74,11 +139,26 @@ func (l *SkipList[T]) Search(val T) bool {
 
 // Insert 在 SkipList 中插入 val，SkipList 中的数据为增序排列(有序集合)
 func (l *SkipList[T]) Insert(val T) {
-	_, update := l.traverse(val, l.level)
+	update := make([]*skipListNode[T], MaxLever)
+	// rank[i] 表示 update[i] 的排名
+	rank := make([]int, MaxLever)
+	cur := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for cur.forward[i] != nil && l.compare(cur.forward[i].val, val) < 0 {
+			rank[i] += cur.span[i]
+			cur = cur.forward[i]
+		}
+		update[i] = cur
+	}
 	level := l.randomLevel()
 	if level > l.level {
 		for i := l.level; i < level; i++ {
 			update[i] = l.head
+			// 新的一层上 head 直接跨到末尾
+			l.head.span[i] = l.length
 		}
 		l.level = level
 	}
@@ -86,6 +166,21 @@ func (l *SkipList[T]) Insert(val T) {
 	for i := 0; i < level; i++ {
 		newNode.forward[i] = update[i].forward[i]
 		update[i].forward[i] = newNode
+		// 新结点的排名为 rank[0] + 1，原来的跨度被新结点分成两段
+		newNode.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	// 更高的层上跨过了新结点
+	for i := level; i < l.level; i++ {
+		update[i].span[i]++
+	}
+	if update[0] != l.head {
+		newNode.backward = update[0]
+	}
+	if newNode.forward[0] != nil {
+		newNode.forward[0].backward = newNode
+	} else {
+		l.tail = newNode
 	}
 	l.length++
 }
@@ -96,16 +191,46 @@ func (l *SkipList[T]) DeleteElement(target T) bool {
 	if node == nil || l.compare(node.val, target) != 0 {
 		return true
 	}
-	// 删除 target 节点
-	for i := 0; i < l.level && update[i].forward[i] == node; i++ {
-		update[i].forward[i] = node.forward[i]
+	l.deleteNode(node, update)
+	return true
+}
+
+// deleteNode 删除结点 node，update[i] 是第 i 层上 node 前面的结点
+func (l *SkipList[T]) deleteNode(node *skipListNode[T], update []*skipListNode[T]) {
+	for i := 0; i < l.level; i++ {
+		if update[i].forward[i] == node {
+			update[i].span[i] += node.span[i] - 1
+			update[i].forward[i] = node.forward[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	if node.forward[0] != nil {
+		node.forward[0].backward = node.backward
+	} else {
+		l.tail = node.backward
 	}
 	// 更新层级
 	for l.level > 1 && l.head.forward[l.level-1] == nil {
 		l.level--
 	}
 	l.length--
-	return true
+}
+
+// findByIndex 返回下标为 index 的结点，调用方保证 index 合法
+func (l *SkipList[T]) findByIndex(index int) *skipListNode[T] {
+	cur := l.head
+	traversed := 0
+	for i := l.level - 1; i >= 0; i-- {
+		for cur.forward[i] != nil && traversed+cur.span[i] <= index+1 {
+			traversed += cur.span[i]
+			cur = cur.forward[i]
+		}
+		if traversed == index+1 {
+			return cur
+		}
+	}
+	return cur
 }
 
 func (l *SkipList[T]) Peek() (T, error) {
@@ -132,17 +257,197 @@ func (l *SkipList[T]) AsSlice() []T {
 	return slice
 }
 
+// All 沿着第 1 层从小到大遍历，遍历过程中不应修改跳表
+func (l *SkipList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for cur := l.head.forward[0]; cur != nil; cur = cur.forward[0] {
+			if !yield(i, cur.val) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+func (l *SkipList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for cur := l.head.forward[0]; cur != nil; cur = cur.forward[0] {
+			if !yield(cur.val) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 沿着 backward 指针从大到小遍历，遍历过程中不应修改跳表
+func (l *SkipList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := l.length - 1
+		for cur := l.tail; cur != nil; cur = cur.backward {
+			if !yield(i, cur.val) {
+				return
+			}
+			i--
+		}
+	}
+}
+
+// Min 返回最小的元素，SkipList 为空的时候返回 false
+func (l *SkipList[T]) Min() (T, bool) {
+	return l.value(l.head.forward[0])
+}
+
+// Max 返回最大的元素，SkipList 为空的时候返回 false
+func (l *SkipList[T]) Max() (T, bool) {
+	return l.value(l.tail)
+}
+
+// Floor 返回小于等于 val 的最大元素，不存在的时候返回 false
+func (l *SkipList[T]) Floor(val T) (T, bool) {
+	return l.value(l.last(val, true))
+}
+
+// Lower 返回严格小于 val 的最大元素，不存在的时候返回 false
+func (l *SkipList[T]) Lower(val T) (T, bool) {
+	return l.value(l.last(val, false))
+}
+
+// Ceiling 返回大于等于 val 的最小元素，不存在的时候返回 false
+func (l *SkipList[T]) Ceiling(val T) (T, bool) {
+	return l.value(l.last(val, false).forward[0])
+}
+
+// Higher 返回严格大于 val 的最小元素，不存在的时候返回 false
+func (l *SkipList[T]) Higher(val T) (T, bool) {
+	return l.value(l.last(val, true).forward[0])
+}
+
+// RangeBetween 从小到大遍历在 lo 和 hi 之间的元素，loInclusive 和 hiInclusive 表示是否包含边界
+// 定位起点是 O(log n) 的，之后沿着第 1 层遍历，遍历过程中不应修改跳表
+func (l *SkipList[T]) RangeBetween(lo, hi T, loInclusive, hiInclusive bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for cur := l.last(lo, !loInclusive).forward[0]; cur != nil && l.beforeHi(cur.val, hi, hiInclusive); cur = cur.forward[0] {
+			if !yield(cur.val) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteRange 删除在 lo 和 hi 之间的所有元素，返回删除的个数
+// 所有被删除的结点前面的结点都是同一条路径，所以只需要查找一次，时间复杂度为 O(log n + 删除的个数)
+func (l *SkipList[T]) DeleteRange(lo, hi T, loInclusive, hiInclusive bool) int {
+	cur, update := l.traverseWhile(l.level, l.before(lo, !loInclusive))
+	cnt := 0
+	for node := cur.forward[0]; node != nil && l.beforeHi(node.val, hi, hiInclusive); node = cur.forward[0] {
+		l.deleteNode(node, update)
+		cnt++
+	}
+	return cnt
+}
+
+// Seek 返回指向第一个大于等于 val 的元素的游标，不存在这样的元素时游标无效
+func (l *SkipList[T]) Seek(val T) *SkipListCursor[T] {
+	return &SkipListCursor[T]{node: l.last(val, false).forward[0]}
+}
+
+// First 返回指向最小元素的游标，SkipList 为空的时候游标无效
+func (l *SkipList[T]) First() *SkipListCursor[T] {
+	return &SkipListCursor[T]{node: l.head.forward[0]}
+}
+
+// Last 返回指向最大元素的游标，SkipList 为空的时候游标无效
+func (l *SkipList[T]) Last() *SkipListCursor[T] {
+	return &SkipListCursor[T]{node: l.tail}
+}
+
+// SkipListCursor 跳表上的双向游标，Next 和 Prev 都是 O(1) 的
+// 移动到第一个元素之前或者最后一个元素之后，游标就变为无效，不能再移动
+// 使用游标的过程中不应修改跳表
+//
+//	for c := l.Seek(lo); c.Valid(); c.Next() {
+//		...
+//	}
+type SkipListCursor[T any] struct {
+	node *skipListNode[T]
+}
+
+// Valid 游标是否指向一个元素
+func (c *SkipListCursor[T]) Valid() bool {
+	return c.node != nil
+}
+
+// Value 返回游标指向的元素，游标无效的时候返回零值
+func (c *SkipListCursor[T]) Value() T {
+	if c.node == nil {
+		var zero T
+		return zero
+	}
+	return c.node.val
+}
+
+// Next 移动到下一个更大的元素
+func (c *SkipListCursor[T]) Next() {
+	if c.node != nil {
+		c.node = c.node.forward[0]
+	}
+}
+
+// Prev 移动到上一个更小的元素
+func (c *SkipListCursor[T]) Prev() {
+	if c.node != nil {
+		c.node = c.node.backward
+	}
+}
+
+// last 返回最后一个小于 val 的结点，inclusive 为 true 的时候返回最后一个小于等于 val 的结点
+// 不存在的时候返回 head
+func (l *SkipList[T]) last(val T, inclusive bool) *skipListNode[T] {
+	cur, _ := l.traverseWhile(l.level, l.before(val, inclusive))
+	return cur
+}
+
+// before 返回判断元素是否排在 val 前面的函数，inclusive 为 true 的时候和 val 相等也算
+func (l *SkipList[T]) before(val T, inclusive bool) func(T) bool {
+	if inclusive {
+		return func(v T) bool { return l.compare(v, val) <= 0 }
+	}
+	return func(v T) bool { return l.compare(v, val) < 0 }
+}
+
+func (l *SkipList[T]) beforeHi(v, hi T, inclusive bool) bool {
+	if inclusive {
+		return l.compare(v, hi) <= 0
+	}
+	return l.compare(v, hi) < 0
+}
+
+func (l *SkipList[T]) value(node *skipListNode[T]) (T, bool) {
+	if node == nil || node == l.head {
+		var zero T
+		return zero, false
+	}
+	return node.val, true
+}
+
 // 查找目标值 val 的插入/删除位置，记录路径信息(update 切片)
 func (l *SkipList[T]) traverse(val T, level int) (*skipListNode[T], []*skipListNode[T]) {
+	return l.traverseWhile(level, l.before(val, false))
+}
+
+// traverseWhile 和 traverse 一样，只是在每一层上找到最后一个满足 before 的节点
+// 跳表是有序的，所以满足 before 的节点必须是一个前缀
+func (l *SkipList[T]) traverseWhile(level int, before func(T) bool) (*skipListNode[T], []*skipListNode[T]) {
 	update := make([]*skipListNode[T], MaxLever)
 	curr := l.head
 	// 从最高层向最底层逐层搜索
 	for i := level - 1; i >= 0; i-- {
-		// 在当前层找到最后一个小于 val 的节点
-		for curr.forward[i] != nil && l.compare(curr.forward[i].val, val) < 0 {
+		// 在当前层找到最后一个满足 before 的节点
+		for curr.forward[i] != nil && before(curr.forward[i].val) {
 			curr = curr.forward[i]
 		}
-		update[i] = curr // 记录该层的最后一个小于 val 的节点
+		update[i] = curr // 记录该层的最后一个满足 before 的节点
 	}
 	return curr, update // 返回最终位置和路径
 }
@@ -3,8 +3,11 @@ package list
 import (
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/zmsocc/generic"
 	"github.com/zmsocc/generic/internal/errs"
+	"golang.org/x/exp/slices"
+	"math/rand"
 	"testing"
 )
 
@@ -286,3 +289,408 @@ func TestSkipList_Peek(t *testing.T) {
 		})
 	}
 }
+
+func TestSkipList_Rank(t *testing.T) {
+	testCases := []struct {
+		name     string
+		list     *SkipList[int]
+		val      int
+		wantRank int
+	}{
+		{
+			name:     "first",
+			list:     NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			val:      1,
+			wantRank: 0,
+		},
+		{
+			name:     "last",
+			list:     NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			val:      5,
+			wantRank: 2,
+		},
+		{
+			// 重复元素返回第一个的下标
+			name:     "duplicate",
+			list:     NewSkipListOf[int]([]int{2, 1, 2, 2, 3}, generic.ComparatorOrdered[int]),
+			val:      2,
+			wantRank: 1,
+		},
+		{
+			name:     "not found",
+			list:     NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			val:      4,
+			wantRank: -1,
+		},
+		{
+			name:     "empty",
+			list:     NewSkipList[int](generic.ComparatorOrdered[int]),
+			val:      1,
+			wantRank: -1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantRank, tc.list.Rank(tc.val))
+		})
+	}
+}
+
+func TestSkipList_DeleteAt(t *testing.T) {
+	testCases := []struct {
+		name      string
+		list      *SkipList[int]
+		index     int
+		wantVal   int
+		wantSlice []int
+		wantErr   error
+	}{
+		{
+			name:      "first",
+			list:      NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			index:     0,
+			wantVal:   1,
+			wantSlice: []int{3, 5},
+		},
+		{
+			name:      "last",
+			list:      NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			index:     2,
+			wantVal:   5,
+			wantSlice: []int{1, 3},
+		},
+		{
+			name:    "out of range",
+			list:    NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			index:   3,
+			wantErr: errs.NewErrIndexOutOfRange(2, 3),
+		},
+		{
+			name:    "empty",
+			list:    NewSkipList[int](generic.ComparatorOrdered[int]),
+			index:   0,
+			wantErr: errs.NewErrIndexOutOfRange(-1, 0),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, err := tc.list.DeleteAt(tc.index)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantSlice, tc.list.AsSlice())
+			assertSpan(t, tc.list)
+		})
+	}
+}
+
+func TestSkipList_Range(t *testing.T) {
+	testCases := []struct {
+		name    string
+		list    *SkipList[int]
+		from    int
+		to      int
+		wantRes []int
+		wantErr error
+	}{
+		{
+			name:    "middle",
+			list:    NewSkipListOf[int]([]int{5, 1, 3, 4, 2}, generic.ComparatorOrdered[int]),
+			from:    1,
+			to:      4,
+			wantRes: []int{2, 3, 4},
+		},
+		{
+			name:    "all",
+			list:    NewSkipListOf[int]([]int{5, 1, 3, 4, 2}, generic.ComparatorOrdered[int]),
+			from:    0,
+			to:      5,
+			wantRes: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:    "empty range",
+			list:    NewSkipListOf[int]([]int{5, 1, 3, 4, 2}, generic.ComparatorOrdered[int]),
+			from:    5,
+			to:      5,
+			wantRes: []int{},
+		},
+		{
+			name:    "from out of range",
+			list:    NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			from:    -1,
+			to:      2,
+			wantErr: errs.NewErrIndexOutOfRange(2, -1),
+		},
+		{
+			name:    "to out of range",
+			list:    NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			from:    1,
+			to:      4,
+			wantErr: errs.NewErrIndexOutOfRange(2, 4),
+		},
+		{
+			name:    "to less than from",
+			list:    NewSkipListOf[int]([]int{5, 1, 3}, generic.ComparatorOrdered[int]),
+			from:    2,
+			to:      1,
+			wantErr: errs.NewErrIndexOutOfRange(2, 1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := tc.list.Range(tc.from, tc.to)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+// 随机插入和删除，和排序之后的切片对比，同时检查每一层的跨度
+func TestSkipList_Indexed_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	l := NewSkipList[int](generic.ComparatorOrdered[int])
+	want := make([]int, 0, 1000)
+	for i := 0; i < 3000; i++ {
+		switch op := r.Intn(4); {
+		case op < 2 || len(want) == 0:
+			val := r.Intn(500)
+			l.Insert(val)
+			idx, _ := slices.BinarySearch(want, val)
+			want = slices.Insert(want, idx, val)
+		case op == 2:
+			idx := r.Intn(len(want))
+			val, err := l.DeleteAt(idx)
+			require.NoError(t, err)
+			require.Equal(t, want[idx], val)
+			want = slices.Delete(want, idx, idx+1)
+		default:
+			val := want[r.Intn(len(want))]
+			l.DeleteElement(val)
+			idx, _ := slices.BinarySearch(want, val)
+			want = slices.Delete(want, idx, idx+1)
+		}
+		require.Equal(t, len(want), l.Len())
+		if len(want) == 0 {
+			continue
+		}
+		idx := r.Intn(len(want))
+		val, err := l.Get(idx)
+		require.NoError(t, err)
+		require.Equal(t, want[idx], val)
+		rank, _ := slices.BinarySearch(want, val)
+		require.Equal(t, rank, l.Rank(val))
+		from := r.Intn(len(want))
+		to := from + r.Intn(len(want)-from+1)
+		res, err := l.Range(from, to)
+		require.NoError(t, err)
+		require.Equal(t, want[from:to], res)
+	}
+	assertSpan(t, l)
+}
+
+func BenchmarkSkipList_Get(b *testing.B) {
+	l := NewSkipList[int](generic.ComparatorOrdered[int])
+	for i := 0; i < 1<<16; i++ {
+		l.Insert(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = l.Get(i & (1<<16 - 1))
+	}
+}
+
+// assertSpan 检查每一层上的跨度之和等于结点在第 1 层上的位置，以及 backward 和 tail 指针
+func assertSpan[T any](t *testing.T, l *SkipList[T]) {
+	pos := make(map[*skipListNode[T]]int, l.length)
+	i := 0
+	var prev *skipListNode[T]
+	for cur := l.head.forward[0]; cur != nil; cur = cur.forward[0] {
+		i++
+		pos[cur] = i
+		require.True(t, cur.backward == prev)
+		prev = cur
+	}
+	require.True(t, l.tail == prev)
+	for level := 0; level < l.level; level++ {
+		traversed := 0
+		for cur := l.head; cur.forward[level] != nil; cur = cur.forward[level] {
+			traversed += cur.span[level]
+			require.Equal(t, pos[cur.forward[level]], traversed)
+		}
+	}
+}
+
+func TestSkipList_Navigation(t *testing.T) {
+	l := NewSkipListOf[int]([]int{50, 10, 30, 20, 40, 30}, generic.ComparatorOrdered[int])
+	testCases := []struct {
+		name   string
+		find   func(val int) (int, bool)
+		val    int
+		want   int
+		wantOk bool
+	}{
+		{name: "floor equal", find: l.Floor, val: 30, want: 30, wantOk: true},
+		{name: "floor between", find: l.Floor, val: 35, want: 30, wantOk: true},
+		{name: "floor too small", find: l.Floor, val: 5},
+		{name: "floor too large", find: l.Floor, val: 60, want: 50, wantOk: true},
+		{name: "lower equal", find: l.Lower, val: 30, want: 20, wantOk: true},
+		{name: "lower first", find: l.Lower, val: 10},
+		{name: "ceiling equal", find: l.Ceiling, val: 30, want: 30, wantOk: true},
+		{name: "ceiling between", find: l.Ceiling, val: 25, want: 30, wantOk: true},
+		{name: "ceiling too large", find: l.Ceiling, val: 60},
+		{name: "higher equal", find: l.Higher, val: 30, want: 40, wantOk: true},
+		{name: "higher last", find: l.Higher, val: 50},
+		{name: "higher too small", find: l.Higher, val: 5, want: 10, wantOk: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := tc.find(tc.val)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+
+	res, ok := l.Min()
+	assert.True(t, ok)
+	assert.Equal(t, 10, res)
+	res, ok = l.Max()
+	assert.True(t, ok)
+	assert.Equal(t, 50, res)
+
+	empty := NewSkipList[int](generic.ComparatorOrdered[int])
+	_, ok = empty.Min()
+	assert.False(t, ok)
+	_, ok = empty.Max()
+	assert.False(t, ok)
+	_, ok = empty.Floor(1)
+	assert.False(t, ok)
+	_, ok = empty.Ceiling(1)
+	assert.False(t, ok)
+}
+
+func TestSkipList_RangeBetween(t *testing.T) {
+	testCases := []struct {
+		name        string
+		lo          int
+		hi          int
+		loInclusive bool
+		hiInclusive bool
+		wantRes     []int
+	}{
+		{name: "closed", lo: 20, hi: 40, loInclusive: true, hiInclusive: true, wantRes: []int{20, 30, 30, 40}},
+		{name: "open", lo: 20, hi: 40, wantRes: []int{30, 30}},
+		{name: "half open", lo: 20, hi: 40, loInclusive: true, wantRes: []int{20, 30, 30}},
+		{name: "not on boundary", lo: 15, hi: 45, wantRes: []int{20, 30, 30, 40}},
+		{name: "all", lo: 0, hi: 100, wantRes: []int{10, 20, 30, 30, 40, 50}},
+		{name: "empty", lo: 30, hi: 30, wantRes: []int{}},
+		{name: "single", lo: 30, hi: 30, loInclusive: true, hiInclusive: true, wantRes: []int{30, 30}},
+		{name: "lo greater than hi", lo: 40, hi: 20, loInclusive: true, hiInclusive: true, wantRes: []int{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewSkipListOf[int]([]int{50, 10, 30, 20, 40, 30}, generic.ComparatorOrdered[int])
+			res := make([]int, 0, len(tc.wantRes))
+			for v := range l.RangeBetween(tc.lo, tc.hi, tc.loInclusive, tc.hiInclusive) {
+				res = append(res, v)
+			}
+			assert.Equal(t, tc.wantRes, res)
+
+			cnt := l.DeleteRange(tc.lo, tc.hi, tc.loInclusive, tc.hiInclusive)
+			assert.Equal(t, len(tc.wantRes), cnt)
+			assert.Equal(t, 6-cnt, l.Len())
+			for v := range l.RangeBetween(tc.lo, tc.hi, tc.loInclusive, tc.hiInclusive) {
+				assert.Fail(t, "element not deleted", v)
+			}
+			assertSpan(t, l)
+		})
+	}
+}
+
+func TestSkipList_Cursor(t *testing.T) {
+	l := NewSkipListOf[int]([]int{50, 10, 30, 20, 40}, generic.ComparatorOrdered[int])
+	res := make([]int, 0, 5)
+	for c := l.Seek(25); c.Valid(); c.Next() {
+		res = append(res, c.Value())
+	}
+	assert.Equal(t, []int{30, 40, 50}, res)
+
+	res = res[:0]
+	for c := l.Last(); c.Valid(); c.Prev() {
+		res = append(res, c.Value())
+	}
+	assert.Equal(t, []int{50, 40, 30, 20, 10}, res)
+
+	// 来回移动
+	c := l.First()
+	c.Next()
+	c.Next()
+	c.Prev()
+	assert.Equal(t, 20, c.Value())
+	c.Prev()
+	c.Prev()
+	assert.False(t, c.Valid())
+	assert.Equal(t, 0, c.Value())
+	c.Next()
+	assert.False(t, c.Valid())
+
+	assert.False(t, l.Seek(60).Valid())
+	assert.False(t, NewSkipList[int](generic.ComparatorOrdered[int]).First().Valid())
+	assert.False(t, NewSkipList[int](generic.ComparatorOrdered[int]).Last().Valid())
+}
+
+// 随机操作，Floor、Ceiling、RangeBetween 和 DeleteRange 的结果和二分查找对比
+func TestSkipList_Navigation_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	l := NewSkipList[int](generic.ComparatorOrdered[int])
+	want := make([]int, 0, 1000)
+	for i := 0; i < 2000; i++ {
+		val := r.Intn(1000)
+		l.Insert(val)
+		idx, _ := slices.BinarySearch(want, val)
+		want = slices.Insert(want, idx, val)
+
+		target := r.Intn(1100)
+		// 第一个大于等于 target 的位置
+		idx, _ = slices.BinarySearch(want, target)
+		res, ok := l.Ceiling(target)
+		require.Equal(t, idx < len(want), ok)
+		if ok {
+			require.Equal(t, want[idx], res)
+		}
+		res, ok = l.Lower(target)
+		require.Equal(t, idx > 0, ok)
+		if ok {
+			require.Equal(t, want[idx-1], res)
+		}
+
+		if i%10 == 0 {
+			lo, hi := r.Intn(1000), r.Intn(1000)
+			from, _ := slices.BinarySearch(want, lo)
+			to, _ := slices.BinarySearch(want, hi+1)
+			if from > to {
+				to = from
+			}
+			got := make([]int, 0, to-from)
+			for v := range l.RangeBetween(lo, hi, true, true) {
+				got = append(got, v)
+			}
+			require.Equal(t, want[from:to], got)
+			if i%20 == 0 {
+				require.Equal(t, to-from, l.DeleteRange(lo, hi, true, true))
+				want = slices.Delete(want, from, to)
+			}
+		}
+	}
+	back := make([]int, 0, len(want))
+	for _, v := range l.Backward() {
+		back = append(back, v)
+	}
+	slices.Reverse(back)
+	assert.Equal(t, want, back)
+	assertSpan(t, l)
+}
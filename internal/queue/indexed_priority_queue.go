@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"errors"
+	"github.com/zmsocc/generic"
+)
+
+var ErrInvalidHandle = errors.New("generic: 句柄不在队列中")
+
+// Handle 入队时返回的句柄，用于之后修改或者删除该元素
+type Handle[T any] struct {
+	val   T
+	index int // 元素在堆中的下标，-1 表示已经不在队列中
+}
+
+// Value 返回句柄对应的元素
+func (h *Handle[T]) Value() T {
+	return h.val
+}
+
+// IndexedPriorityQueue 带索引的优先级队列
+// 每个元素都记录了自己在堆中的下标，因此可以在 O(log n) 内修改或者删除任意元素
+type IndexedPriorityQueue[T any] struct {
+	pq *PriorityQueue[*Handle[T]]
+}
+
+// NewIndexedPriorityQueue 创建一个带索引的优先级队列
+// capacity <= 0 表示无界队列
+func NewIndexedPriorityQueue[T any](capacity int, compare generic.Comparator[T]) *IndexedPriorityQueue[T] {
+	pq := NewPriorityQueue[*Handle[T]](capacity, func(src *Handle[T], dst *Handle[T]) int {
+		return compare(src.val, dst.val)
+	})
+	pq.onSwap = func(data []*Handle[T], i, j int) {
+		data[i].index = i
+		data[j].index = j
+	}
+	return &IndexedPriorityQueue[T]{pq: pq}
+}
+
+func (q *IndexedPriorityQueue[T]) Len() int {
+	return q.pq.Len()
+}
+
+func (q *IndexedPriorityQueue[T]) Cap() int {
+	return q.pq.Cap()
+}
+
+// Enqueue 入队，返回元素对应的句柄
+func (q *IndexedPriorityQueue[T]) Enqueue(t T) (*Handle[T], error) {
+	h := &Handle[T]{val: t, index: q.pq.Len()}
+	if err := q.pq.Enqueue(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (q *IndexedPriorityQueue[T]) Dequeue() (T, error) {
+	h, err := q.pq.Dequeue()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	h.index = -1
+	return h.val, nil
+}
+
+func (q *IndexedPriorityQueue[T]) Peek() (T, error) {
+	h, err := q.pq.Peek()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return h.val, nil
+}
+
+// Contains 判断句柄对应的元素是否还在队列中
+func (q *IndexedPriorityQueue[T]) Contains(h *Handle[T]) bool {
+	return h != nil && h.index >= 0 && h.index < q.pq.Len() && q.pq.data[h.index] == h
+}
+
+// Update 将句柄对应的元素修改为 val，并从原来的位置向上或者向下调整
+func (q *IndexedPriorityQueue[T]) Update(h *Handle[T], val T) error {
+	if !q.Contains(h) {
+		return ErrInvalidHandle
+	}
+	h.val = val
+	q.pq.fix(h.index)
+	return nil
+}
+
+// Remove 删除句柄对应的元素
+func (q *IndexedPriorityQueue[T]) Remove(h *Handle[T]) (T, error) {
+	if !q.Contains(h) {
+		var zero T
+		return zero, ErrInvalidHandle
+	}
+	q.pq.remove(h.index)
+	h.index = -1
+	return h.val, nil
+}
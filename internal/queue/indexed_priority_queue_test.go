@@ -0,0 +1,234 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestIndexedPriorityQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		val      int
+		wantErr  error
+		wantPeek int
+	}{
+		{
+			name:     "有界空队列",
+			capacity: 3,
+			data:     []int{},
+			val:      10,
+			wantPeek: 10,
+		},
+		{
+			name:     "有界满队列",
+			capacity: 3,
+			data:     []int{1, 2, 3},
+			val:      10,
+			wantErr:  ErrOutOfCapacity,
+		},
+		{
+			name:     "无界非空队列",
+			capacity: 0,
+			data:     []int{6, 5, 4, 3, 2, 1},
+			val:      0,
+			wantPeek: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewIndexedPriorityQueue[int](tc.capacity, generic.ComparatorOrdered[int])
+			for _, v := range tc.data {
+				_, err := q.Enqueue(v)
+				require.NoError(t, err)
+			}
+			h, err := q.Enqueue(tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				assert.Nil(t, h)
+				return
+			}
+			assert.True(t, q.Contains(h))
+			assert.Equal(t, tc.val, h.Value())
+			peek, err := q.Peek()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPeek, peek)
+			assertIndexed(t, q)
+		})
+	}
+}
+
+func TestIndexedPriorityQueue_Update(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      []int
+		updateIdx int // 修改第几个入队的元素
+		val       int
+		wantOrder []int
+	}{
+		{
+			name:      "decrease key to top",
+			data:      []int{6, 5, 4, 3, 2, 1},
+			updateIdx: 0,
+			val:       0,
+			wantOrder: []int{0, 1, 2, 3, 4, 5},
+		},
+		{
+			name:      "increase key to bottom",
+			data:      []int{6, 5, 4, 3, 2, 1},
+			updateIdx: 5,
+			val:       10,
+			wantOrder: []int{2, 3, 4, 5, 6, 10},
+		},
+		{
+			name:      "same key",
+			data:      []int{6, 5, 4, 3, 2, 1},
+			updateIdx: 2,
+			val:       4,
+			wantOrder: []int{1, 2, 3, 4, 5, 6},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewIndexedPriorityQueue[int](0, generic.ComparatorOrdered[int])
+			handles := make([]*Handle[int], 0, len(tc.data))
+			for _, v := range tc.data {
+				h, err := q.Enqueue(v)
+				require.NoError(t, err)
+				handles = append(handles, h)
+			}
+			err := q.Update(handles[tc.updateIdx], tc.val)
+			require.NoError(t, err)
+			assertIndexed(t, q)
+			assert.Equal(t, tc.wantOrder, drainIndexed(t, q))
+		})
+	}
+	t.Run("invalid handle", func(t *testing.T) {
+		q := NewIndexedPriorityQueue[int](0, generic.ComparatorOrdered[int])
+		h, err := q.Enqueue(1)
+		require.NoError(t, err)
+		_, err = q.Dequeue()
+		require.NoError(t, err)
+		assert.False(t, q.Contains(h))
+		assert.Equal(t, ErrInvalidHandle, q.Update(h, 2))
+		assert.Equal(t, ErrInvalidHandle, q.Update(nil, 2))
+		// 其他队列的句柄
+		other := NewIndexedPriorityQueue[int](0, generic.ComparatorOrdered[int])
+		oh, err := other.Enqueue(1)
+		require.NoError(t, err)
+		_, err = q.Enqueue(1)
+		require.NoError(t, err)
+		assert.False(t, q.Contains(oh))
+	})
+}
+
+func TestIndexedPriorityQueue_Remove(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      []int
+		removeIdx int // 删除第几个入队的元素
+		wantOrder []int
+	}{
+		{
+			name:      "remove top",
+			data:      []int{6, 5, 4, 3, 2, 1},
+			removeIdx: 5,
+			wantOrder: []int{2, 3, 4, 5, 6},
+		},
+		{
+			name:      "remove middle",
+			data:      []int{6, 5, 4, 3, 2, 1},
+			removeIdx: 2,
+			wantOrder: []int{1, 2, 3, 5, 6},
+		},
+		{
+			name:      "remove only",
+			data:      []int{1},
+			removeIdx: 0,
+			wantOrder: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewIndexedPriorityQueue[int](0, generic.ComparatorOrdered[int])
+			handles := make([]*Handle[int], 0, len(tc.data))
+			for _, v := range tc.data {
+				h, err := q.Enqueue(v)
+				require.NoError(t, err)
+				handles = append(handles, h)
+			}
+			h := handles[tc.removeIdx]
+			val, err := q.Remove(h)
+			require.NoError(t, err)
+			assert.Equal(t, tc.data[tc.removeIdx], val)
+			assert.False(t, q.Contains(h))
+			_, err = q.Remove(h)
+			assert.Equal(t, ErrInvalidHandle, err)
+			assertIndexed(t, q)
+			assert.Equal(t, tc.wantOrder, drainIndexed(t, q))
+		})
+	}
+}
+
+// 随机执行入队、出队、修改和删除，和排序的结果做对比
+func TestIndexedPriorityQueue_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	q := NewIndexedPriorityQueue[int](0, generic.ComparatorOrdered[int])
+	handles := make([]*Handle[int], 0, 1000)
+	for i := 0; i < 1000; i++ {
+		h, err := q.Enqueue(r.Intn(10000))
+		require.NoError(t, err)
+		handles = append(handles, h)
+	}
+	for i := 0; i < 2000; i++ {
+		h := handles[r.Intn(len(handles))]
+		if !q.Contains(h) {
+			continue
+		}
+		switch r.Intn(3) {
+		case 0:
+			require.NoError(t, q.Update(h, r.Intn(10000)))
+		case 1:
+			_, err := q.Remove(h)
+			require.NoError(t, err)
+		default:
+			_, err := q.Dequeue()
+			require.NoError(t, err)
+		}
+	}
+	assertIndexed(t, q)
+	want := make([]int, 0, q.Len())
+	for _, h := range handles {
+		if q.Contains(h) {
+			want = append(want, h.Value())
+		}
+	}
+	sort.Ints(want)
+	assert.Equal(t, want, drainIndexed(t, q))
+}
+
+// assertIndexed 校验每个句柄记录的下标和它在堆中的位置一致，并且满足最小堆的性质
+func assertIndexed(t *testing.T, q *IndexedPriorityQueue[int]) {
+	data := q.pq.data
+	for i, h := range data {
+		assert.Equal(t, i, h.index)
+		if i > 0 {
+			assert.LessOrEqual(t, data[(i-1)/2].val, h.val)
+		}
+	}
+}
+
+func drainIndexed(t *testing.T, q *IndexedPriorityQueue[int]) []int {
+	res := make([]int, 0, q.Len())
+	for q.Len() > 0 {
+		val, err := q.Dequeue()
+		require.NoError(t, err)
+		res = append(res, val)
+	}
+	return res
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/zmsocc/generic"
 	"github.com/zmsocc/generic/internal/slice"
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -11,23 +12,59 @@ var (
 	ErrEmptyQueue    = errors.New("generic: 队列为空")
 )
 
+const defaultArity = 2
+
 type PriorityQueue[T any] struct {
 	compare  generic.Comparator[T]
 	capacity int
 	data     []T
+	// arity 每个节点最多有几个子节点，默认是二叉堆
+	// 多叉堆的高度更低，入队更快；出队需要比较更多的子节点，但是对缓存更友好，元素多的时候通常更快
+	arity int
+	// onSwap 堆中两个元素交换位置之后调用，IndexedPriorityQueue 用它来维护元素的下标
+	onSwap func(data []T, i, j int)
 }
 
-func NewPriorityQueue[T any](capacity int, compare generic.Comparator[T]) *PriorityQueue[T] {
+// Option PriorityQueue 的可选配置
+type Option[T any] func(p *PriorityQueue[T])
+
+// WithArity 指定堆的叉数，通常使用 2、4 或者 8，小于 2 的时候使用二叉堆
+func WithArity[T any](arity int) Option[T] {
+	return func(p *PriorityQueue[T]) {
+		p.arity = max(arity, defaultArity)
+	}
+}
+
+func NewPriorityQueue[T any](capacity int, compare generic.Comparator[T], opts ...Option[T]) *PriorityQueue[T] {
 	sliceCap := capacity
 	if capacity < 1 {
 		capacity = 0
 		sliceCap = 64
 	}
-	return &PriorityQueue[T]{
+	p := &PriorityQueue[T]{
 		compare:  compare,
 		capacity: capacity,
 		data:     make([]T, 0, sliceCap),
+		arity:    defaultArity,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewPriorityQueueOf 用 src 构造优先级队列，时间复杂度为 O(n)
+// 会复制 src，不会修改 src 本身
+// capacity <= 0 表示无界队列；有界的时候 src 的长度不能超过 capacity，否则返回 ErrOutOfCapacity
+func NewPriorityQueueOf[T any](src []T, capacity int, compare generic.Comparator[T], opts ...Option[T]) (*PriorityQueue[T], error) {
+	if capacity > 0 && len(src) > capacity {
+		return nil, ErrOutOfCapacity
 	}
+	p := NewPriorityQueue[T](max(capacity, len(src)), compare, opts...)
+	p.capacity = max(capacity, 0)
+	p.data = append(p.data, src...)
+	p.heapify()
+	return p, nil
 }
 
 func (p *PriorityQueue[T]) Len() int {
@@ -63,13 +100,26 @@ func (p *PriorityQueue[T]) Enqueue(t T) error {
 		return ErrOutOfCapacity
 	}
 	p.data = append(p.data, t)
-	node := len(p.data) - 1
-	parent := (node - 1) / 2 // 二叉堆父子关系公式：parent = (child - 1) / 2
-	for parent >= 0 && p.compare(p.data[node], p.data[parent]) < 0 {
-		p.data[parent], p.data[node] = p.data[node], p.data[parent]
-		node = parent
-		parent = (parent - 1) >> 1
+	p.heapUp(p.data, len(p.data)-1)
+	return nil
+}
+
+// EnqueueAll 批量入队
+// 有界队列放不下所有元素的时候返回 ErrOutOfCapacity，并且一个元素都不会入队
+// 入队元素比较多的时候直接追加到末尾再整体建堆，时间复杂度为 O(n + k)
+func (p *PriorityQueue[T]) EnqueueAll(src ...T) error {
+	if !p.isBoundless() && p.Len()+len(src) > p.capacity {
+		return ErrOutOfCapacity
 	}
+	if len(src) < p.Len() {
+		for _, t := range src {
+			p.data = append(p.data, t)
+			p.heapUp(p.data, len(p.data)-1)
+		}
+		return nil
+	}
+	p.data = append(p.data, src...)
+	p.heapify()
 	return nil
 }
 
@@ -78,12 +128,85 @@ func (p *PriorityQueue[T]) Dequeue() (T, error) {
 		var zero T
 		return zero, ErrEmptyQueue
 	}
-	pop := p.data[0]
-	p.data[0] = p.data[len(p.data)-1]
-	p.data = p.data[:len(p.data)-1]
+	return p.remove(0), nil
+}
+
+// ReplaceTop 用 t 替换堆顶元素并返回原来的堆顶，队列为空的时候返回 ErrEmptyQueue
+// 相当于先 Dequeue 再 Enqueue，但是只需要向下调整一次，也不会触发缩容
+func (p *PriorityQueue[T]) ReplaceTop(t T) (T, error) {
+	if p.isEmpty() {
+		var zero T
+		return zero, ErrEmptyQueue
+	}
+	top := p.data[0]
+	p.data[0] = t
+	p.fix(0)
+	return top, nil
+}
+
+// DequeueN 按照优先级顺序出队最多 n 个元素，队列为空的时候返回 ErrEmptyQueue
+func (p *PriorityQueue[T]) DequeueN(n int) ([]T, error) {
+	if p.isEmpty() {
+		return nil, ErrEmptyQueue
+	}
+	n = max(min(n, p.Len()), 0)
+	res := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, p.remove(0))
+	}
+	return res, nil
+}
+
+// Drain 按照优先级顺序出队所有元素，之后队列为空
+func (p *PriorityQueue[T]) Drain() []T {
+	res := make([]T, 0, p.Len())
+	for !p.isEmpty() {
+		res = append(res, p.remove(0))
+	}
+	return res
+}
+
+// AsSlice 返回堆中元素的副本，顺序为堆内部的存储顺序，不会修改队列
+func (p *PriorityQueue[T]) AsSlice() []T {
+	return slices.Clone(p.data)
+}
+
+// SortedSlice 返回按照优先级排序的元素副本，不会修改队列
+func (p *PriorityQueue[T]) SortedSlice() []T {
+	res := slices.Clone(p.data)
+	slices.SortFunc(res, p.compare)
+	return res
+}
+
+// UpdateAll 用 fn 的返回值替换队列中的每一个元素，然后重新建堆，时间复杂度为 O(n)
+// 适用于所有元素的优先级同时发生变化的场景，例如按照等待时间老化
+func (p *PriorityQueue[T]) UpdateAll(fn func(t T) T) {
+	for i, t := range p.data {
+		p.data[i] = fn(t)
+	}
+	p.heapify()
+}
+
+// remove 删除下标为 i 的元素：先和最后一个元素交换，再从 i 开始重新调整堆
+func (p *PriorityQueue[T]) remove(i int) T {
+	last := len(p.data) - 1
+	pop := p.data[i]
+	p.swap(p.data, i, last)
+	var zero T
+	p.data[last] = zero // 为了释放内存，GC
+	p.data = p.data[:last]
 	p.shrinkIfNecessary()
-	p.heapSmall(p.data, len(p.data), 0)
-	return pop, nil
+	if i < last {
+		p.fix(i)
+	}
+	return pop
+}
+
+// fix 下标为 i 的元素发生变化之后，向上或者向下调整以维护最小堆的性质
+func (p *PriorityQueue[T]) fix(i int) {
+	if !p.heapUp(p.data, i) {
+		p.heapSmall(p.data, len(p.data), i)
+	}
 }
 
 func (p *PriorityQueue[T]) shrinkIfNecessary() {
@@ -92,20 +215,53 @@ func (p *PriorityQueue[T]) shrinkIfNecessary() {
 	}
 }
 
+// heapify 自底向上建堆，时间复杂度为 O(n)
+func (p *PriorityQueue[T]) heapify() {
+	n := len(p.data)
+	// 从最后一个非叶子节点开始
+	for i := (n - 2) / p.arity; i >= 0; i-- {
+		p.heapSmall(p.data, n, i)
+	}
+}
+
+// 从节点 i 开始，向上调整以维护最小堆的性质，返回节点是否发生了移动
+func (p *PriorityQueue[T]) heapUp(data []T, i int) bool {
+	node := i
+	// d 叉堆父子关系公式：parent = (child - 1) / d，二叉堆即 parent = (child - 1) / 2
+	for node > 0 {
+		parent := (node - 1) / p.arity
+		if p.compare(data[node], data[parent]) >= 0 {
+			break
+		}
+		p.swap(data, parent, node)
+		node = parent
+	}
+	return node != i
+}
+
 // 从节点 i 开始，向下调整以维护最小堆的性质
 func (p *PriorityQueue[T]) heapSmall(data []T, n, i int) {
 	minPos := i
 	for {
-		if left := i*2 + 1; left < n && p.compare(data[left], data[minPos]) < 0 {
-			minPos = left
-		}
-		if right := i*2 + 2; right < n && p.compare(data[right], data[minPos]) < 0 {
-			minPos = right
+		// 节点 i 的子节点为 [i*d + 1, i*d + d]
+		first := i*p.arity + 1
+		last := min(first+p.arity, n)
+		for child := first; child < last; child++ {
+			if p.compare(data[child], data[minPos]) < 0 {
+				minPos = child
+			}
 		}
 		if minPos == i {
 			break
 		}
-		data[i], data[minPos] = data[minPos], data[i]
+		p.swap(data, i, minPos)
 		i = minPos
 	}
 }
+
+func (p *PriorityQueue[T]) swap(data []T, i, j int) {
+	data[i], data[j] = data[j], data[i]
+	if p.onSwap != nil {
+		p.onSwap(data, i, j)
+	}
+}
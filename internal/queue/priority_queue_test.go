@@ -5,6 +5,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/zmsocc/generic"
+	"golang.org/x/exp/slices"
+	"math/rand"
 	"testing"
 )
 
@@ -184,6 +186,360 @@ func TestPriorityQueue_Peek(t *testing.T) {
 	}
 }
 
+func TestNewPriorityQueueOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      []int
+		capacity int
+		wantErr  error
+		wantCap  int
+		wantRes  []int
+	}{
+		{
+			name:     "无界队列",
+			src:      []int{6, 5, 4, 3, 2, 1},
+			capacity: 0,
+			wantCap:  0,
+			wantRes:  []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "有界队列",
+			src:      []int{3, 1, 2},
+			capacity: 5,
+			wantCap:  5,
+			wantRes:  []int{1, 2, 3},
+		},
+		{
+			name:     "有界队列刚好满",
+			src:      []int{3, 1, 2},
+			capacity: 3,
+			wantCap:  3,
+			wantRes:  []int{1, 2, 3},
+		},
+		{
+			name:     "超出容量",
+			src:      []int{3, 1, 2},
+			capacity: 2,
+			wantErr:  ErrOutOfCapacity,
+		},
+		{
+			name:     "空切片",
+			src:      []int{},
+			capacity: 0,
+			wantCap:  0,
+			wantRes:  []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := append([]int{}, tc.src...)
+			p, err := NewPriorityQueueOf(tc.src, tc.capacity, generic.ComparatorOrdered[int])
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			// 不会修改 src
+			assert.Equal(t, src, tc.src)
+			assert.Equal(t, tc.wantCap, p.Cap())
+			assertHeap(t, p)
+			assert.Equal(t, tc.wantRes, p.Drain())
+		})
+	}
+}
+
+func TestPriorityQueue_EnqueueAll(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		src      []int
+		wantErr  error
+		wantLen  int
+		wantRes  []int
+	}{
+		{
+			name:     "少量元素逐个入队",
+			capacity: 0,
+			data:     []int{6, 5, 4, 3, 2, 1},
+			src:      []int{0, 7},
+			wantLen:  8,
+			wantRes:  []int{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:     "大量元素整体建堆",
+			capacity: 0,
+			data:     []int{4, 2},
+			src:      []int{6, 5, 3, 1, 0},
+			wantLen:  7,
+			wantRes:  []int{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "超出容量",
+			capacity: 3,
+			data:     []int{1, 2},
+			src:      []int{3, 4},
+			wantErr:  ErrOutOfCapacity,
+			wantLen:  2,
+			wantRes:  []int{1, 2},
+		},
+		{
+			name:     "空",
+			capacity: 3,
+			data:     []int{},
+			src:      []int{},
+			wantLen:  0,
+			wantRes:  []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := priorityQueueOf(tc.capacity, tc.data, generic.ComparatorOrdered[int])
+			require.NotNil(t, p)
+			err := p.EnqueueAll(tc.src...)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, p.Len())
+			assertHeap(t, p)
+			assert.Equal(t, tc.wantRes, p.Drain())
+		})
+	}
+}
+
+func TestPriorityQueue_DequeueN(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []int
+		n       int
+		wantErr error
+		wantRes []int
+		wantLen int
+	}{
+		{
+			name:    "empty queue",
+			data:    []int{},
+			n:       3,
+			wantErr: ErrEmptyQueue,
+		},
+		{
+			name:    "less than len",
+			data:    []int{6, 5, 4, 3, 2, 1},
+			n:       3,
+			wantRes: []int{1, 2, 3},
+			wantLen: 3,
+		},
+		{
+			name:    "more than len",
+			data:    []int{6, 5, 4},
+			n:       10,
+			wantRes: []int{4, 5, 6},
+			wantLen: 0,
+		},
+		{
+			name:    "negative n",
+			data:    []int{6, 5, 4},
+			n:       -1,
+			wantRes: []int{},
+			wantLen: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := priorityQueueOf(0, tc.data, generic.ComparatorOrdered[int])
+			require.NotNil(t, p)
+			res, err := p.DequeueN(tc.n)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantLen, p.Len())
+			assertHeap(t, p)
+		})
+	}
+}
+
+func TestPriorityQueue_ReplaceTop(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []int
+		val     int
+		wantErr error
+		wantTop int
+		wantRes []int
+	}{
+		{
+			name:    "empty queue",
+			data:    []int{},
+			val:     1,
+			wantErr: ErrEmptyQueue,
+			wantRes: []int{},
+		},
+		{
+			// 新元素比原来的所有元素都大，需要一直调整到叶子节点
+			name:    "largest",
+			data:    []int{6, 5, 4, 3, 2, 1},
+			val:     10,
+			wantTop: 1,
+			wantRes: []int{2, 3, 4, 5, 6, 10},
+		},
+		{
+			name:    "middle",
+			data:    []int{6, 5, 4, 3, 2, 1},
+			val:     4,
+			wantTop: 1,
+			wantRes: []int{2, 3, 4, 4, 5, 6},
+		},
+		{
+			// 新元素依旧是最小的，不需要调整
+			name:    "smallest",
+			data:    []int{6, 5, 4, 3, 2, 1},
+			val:     0,
+			wantTop: 1,
+			wantRes: []int{0, 2, 3, 4, 5, 6},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := priorityQueueOf(0, tc.data, generic.ComparatorOrdered[int])
+			require.NotNil(t, p)
+			top, err := p.ReplaceTop(tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTop, top)
+			assert.Equal(t, len(tc.data), p.Len())
+			assertHeap(t, p)
+			assert.Equal(t, tc.wantRes, p.Drain())
+		})
+	}
+}
+
+func TestPriorityQueue_AsSlice(t *testing.T) {
+	p := priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, generic.ComparatorOrdered[int])
+	require.NotNil(t, p)
+	res := p.AsSlice()
+	assert.Equal(t, p.data, res)
+	res[0] = 100
+	assert.Equal(t, 1, p.data[0])
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, p.SortedSlice())
+	// 不会修改队列
+	assert.Equal(t, 6, p.Len())
+	assertHeap(t, p)
+}
+
+func TestPriorityQueue_UpdateAll(t *testing.T) {
+	p := priorityQueueOf(0, []int{1, 2, 3, 4, 5, 6}, generic.ComparatorOrdered[int])
+	require.NotNil(t, p)
+	// 优先级整体反转之后依旧满足堆的性质
+	p.UpdateAll(func(t int) int {
+		return 10 - t
+	})
+	assertHeap(t, p)
+	assert.Equal(t, []int{4, 5, 6, 7, 8, 9}, p.Drain())
+}
+
+func TestPriorityQueue_Arity(t *testing.T) {
+	testCases := []struct {
+		name      string
+		arity     int
+		wantArity int
+	}{
+		{name: "binary", arity: 2, wantArity: 2},
+		{name: "4-ary", arity: 4, wantArity: 4},
+		{name: "8-ary", arity: 8, wantArity: 8},
+		{name: "3-ary", arity: 3, wantArity: 3},
+		{name: "invalid", arity: 1, wantArity: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(int64(tc.arity)))
+			src := make([]int, 200)
+			for i := range src {
+				src[i] = r.Intn(100)
+			}
+			want := slices.Clone(src)
+			slices.Sort(want)
+
+			p := NewPriorityQueue[int](0, generic.ComparatorOrdered[int], WithArity[int](tc.arity))
+			assert.Equal(t, tc.wantArity, p.arity)
+			for _, v := range src {
+				require.NoError(t, p.Enqueue(v))
+				assertHeap(t, p)
+			}
+			// 出队和入队交替进行
+			for i := 0; i < 50; i++ {
+				_, err := p.Dequeue()
+				require.NoError(t, err)
+				require.NoError(t, p.Enqueue(want[0]-1-i))
+				assertHeap(t, p)
+			}
+			res := p.Drain()
+			assert.True(t, slices.IsSorted(res))
+			assert.Len(t, res, len(src))
+
+			// 批量建堆
+			p, err := NewPriorityQueueOf(src, 0, generic.ComparatorOrdered[int], WithArity[int](tc.arity))
+			require.NoError(t, err)
+			assertHeap(t, p)
+			assert.Equal(t, want, p.Drain())
+		})
+	}
+}
+
+func BenchmarkPriorityQueue_Arity(b *testing.B) {
+	const size = 100000
+	r := rand.New(rand.NewSource(1))
+	src := make([]int, size)
+	for i := range src {
+		src[i] = r.Int()
+	}
+	for _, arity := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("enqueue arity %d", arity), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p := NewPriorityQueue[int](size, generic.ComparatorOrdered[int], WithArity[int](arity))
+				for _, v := range src {
+					_ = p.Enqueue(v)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("dequeue arity %d", arity), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				p, _ := NewPriorityQueueOf(src, size, generic.ComparatorOrdered[int], WithArity[int](arity))
+				b.StartTimer()
+				for p.Len() > 0 {
+					_, _ = p.Dequeue()
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewPriorityQueueOf(b *testing.B) {
+	src := make([]int, 100000)
+	for i := range src {
+		src[i] = len(src) - i
+	}
+	b.Run("heapify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = NewPriorityQueueOf(src, 0, generic.ComparatorOrdered[int])
+		}
+	})
+	b.Run("enqueue one by one", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p := NewPriorityQueue(0, generic.ComparatorOrdered[int])
+			for _, v := range src {
+				_ = p.Enqueue(v)
+			}
+		}
+	})
+}
+
+// assertHeap 校验满足最小堆的性质
+func assertHeap(t *testing.T, p *PriorityQueue[int]) {
+	for i := 1; i < len(p.data); i++ {
+		assert.LessOrEqual(t, p.data[(i-1)/p.arity], p.data[i])
+	}
+}
+
 func priorityQueueOf(capacity int, data []int, compare generic.Comparator[int]) *PriorityQueue[int] {
 	p := NewPriorityQueue(capacity, compare)
 	for _, v := range data {
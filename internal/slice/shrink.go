@@ -1,6 +1,8 @@
 package slice
 
-func calCapacity(c int, l int) (int, bool) {
+// ShrinkCapacity 返回容量为 c、长度为 l 的切片缩容之后的容量，以及是否需要缩容
+// 不能直接使用 Shrink 的容器（例如环形缓冲区）可以用它计算容量，再按照自己的方式复制元素
+func ShrinkCapacity(c int, l int) (int, bool) {
 	if l <= 0 {
 		return 0, false
 	}
@@ -17,7 +19,7 @@ func calCapacity(c int, l int) (int, bool) {
 
 func Shrink[T any](src []T) []T {
 	c, l := cap(src), len(src)
-	n, changed := calCapacity(c, l)
+	n, changed := ShrinkCapacity(c, l)
 	if !changed {
 		return src
 	}
@@ -3,8 +3,11 @@ package list
 import (
 	"github.com/zmsocc/generic/internal/errs"
 	"github.com/zmsocc/generic/internal/slice"
+	"iter"
 )
 
+var _ List[any] = &ArrayList[any]{}
+
 // ArrayList 基于切片的简单封装
 type ArrayList[T any] struct {
 	vals []T
@@ -40,9 +43,13 @@ func (a *ArrayList[T]) Append(src ...T) error {
 }
 
 // Add 在 ArrayList 下标为 index 处插入元素 val
-func (a *ArrayList[T]) Add(val T, index int) (err error) {
-	a.vals, err = slice.Add(a.vals, val, index)
-	return err
+func (a *ArrayList[T]) Add(val T, index int) error {
+	res, err := slice.Add(a.vals, val, index)
+	if err != nil {
+		return err
+	}
+	a.vals = res
+	return nil
 }
 
 // Set 设置 ArrayList 下标为 index 处的值为 val
@@ -87,3 +94,34 @@ func (a *ArrayList[T]) AsSlice() []T {
 	copy(res, a.vals)
 	return res
 }
+
+// All 直接遍历底层切片，遍历过程中修改 ArrayList 的结果和修改切片一样
+func (a *ArrayList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range a.vals {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+func (a *ArrayList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range a.vals {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (a *ArrayList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := len(a.vals) - 1; i >= 0; i-- {
+			if !yield(i, a.vals[i]) {
+				return
+			}
+		}
+	}
+}
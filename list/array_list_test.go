@@ -370,3 +370,8 @@ func TestArrayList_Delete_Shrink(t *testing.T) {
 		})
 	}
 }
+
+func TestArrayList_Iter(t *testing.T) {
+	testIter(t, NewArrayListOf[int]([]int{1, 2, 3, 4}), []int{1, 2, 3, 4})
+	testIter(t, NewArrayList[int](0), []int{})
+}
@@ -1,9 +1,12 @@
 package list
 
 import (
+	"iter"
 	"sync"
 )
 
+var _ List[any] = &ConcurrentList[any]{}
+
 type ConcurrentList[T any] struct {
 	List[T]
 	lock sync.RWMutex
@@ -56,3 +59,35 @@ func (c *ConcurrentList[T]) AsSlice() []T {
 	defer c.lock.RUnlock()
 	return c.List.AsSlice()
 }
+
+// All 遍历调用时的快照，遍历过程中不持有锁，所以可以在循环体内修改 ConcurrentList
+func (c *ConcurrentList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range c.AsSlice() {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+func (c *ConcurrentList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range c.AsSlice() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (c *ConcurrentList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		vals := c.AsSlice()
+		for i := len(vals) - 1; i >= 0; i-- {
+			if !yield(i, vals[i]) {
+				return
+			}
+		}
+	}
+}
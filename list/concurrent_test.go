@@ -285,3 +285,15 @@ func TestConcurrentList_Cap(t *testing.T) {
 //	// 但是地址不同，也就是意味着 slice 必须是一个新创建的
 //	assert.Equal(t, aAddr, sliceAddr)
 //}
+
+func TestConcurrentList_Iter(t *testing.T) {
+	testIter(t, newConcurrentList([]int{1, 2, 3}), []int{1, 2, 3})
+	testIter(t, newConcurrentList([]int{}), []int{})
+
+	// 遍历过程中不持有锁，可以修改
+	l := newConcurrentList([]int{1, 2, 3})
+	for v := range l.Values() {
+		assert.NoError(t, l.Append(v))
+	}
+	assert.Equal(t, []int{1, 2, 3, 1, 2, 3}, l.AsSlice())
+}
@@ -4,104 +4,208 @@ import (
 	"github.com/zmsocc/generic/internal/errs"
 	"github.com/zmsocc/generic/internal/slice"
 	"golang.org/x/exp/slices"
+	"iter"
 	"sync"
+	"sync/atomic"
 )
 
-// CopyOnWriteArrayList 基于切片的简单封装，写时加锁，读不加锁，适合于读多写少的场景
+var _ List[any] = &CopyOnWriteArrayList[any]{}
+
+// CopyOnWriteArrayList 基于切片的简单封装，写时复制，适合于读多写少的场景
+// 每次写操作都会复制一份新的切片，修改之后通过原子操作替换，
+// 已经发布出去的切片不会再被修改，所以读操作不需要加锁，只需要原子地读取当前切片
+// 写操作之间用锁互斥，避免并发写的时候丢失更新
 type CopyOnWriteArrayList[T any] struct {
-	vals  []T
-	mutex *sync.Mutex
+	vals  atomic.Pointer[[]T]
+	mutex sync.Mutex
 }
 
 func NewCopyOnWriteArrayList[T any]() *CopyOnWriteArrayList[T] {
-	m := &sync.Mutex{}
-	return &CopyOnWriteArrayList[T]{
-		vals:  make([]T, 0),
-		mutex: m,
-	}
+	c := &CopyOnWriteArrayList[T]{}
+	c.store(make([]T, 0))
+	return c
 }
 
 // NewCopyOnWriteArrayListOf 直接使用 src，会执行复制
 func NewCopyOnWriteArrayListOf[T any](src []T) *CopyOnWriteArrayList[T] {
 	items := make([]T, len(src))
 	copy(items, src)
-	m := &sync.Mutex{}
-	return &CopyOnWriteArrayList[T]{
-		vals:  items,
-		mutex: m,
-	}
+	c := &CopyOnWriteArrayList[T]{}
+	c.store(items)
+	return c
 }
 
 func (c *CopyOnWriteArrayList[T]) Get(index int) (t T, e error) {
-	length := c.Len()
+	vals := c.load()
+	length := len(vals)
 	if index < 0 || index >= length {
 		return t, errs.NewErrIndexOutOfRange(length-1, index)
 	}
-	return c.vals[index], nil
+	return vals[index], nil
 }
 
-func (c *CopyOnWriteArrayList[T]) Append(src []T) error {
+func (c *CopyOnWriteArrayList[T]) Append(src ...T) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	newItems := slices.Clone(c.vals) // go:1.21 版本之后可用
+	vals := c.load()
+	newItems := make([]T, 0, len(vals)+len(src))
+	newItems = append(newItems, vals...)
 	newItems = append(newItems, src...)
-	c.vals = newItems
+	c.store(newItems)
 	return nil
 }
 
 func (c *CopyOnWriteArrayList[T]) Add(src T, index int) (err error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	newItems := slices.Clone(c.vals)
+	newItems := slices.Clone(c.load())
 	newItems, err = slice.Add(newItems, src, index)
 	if err != nil {
 		return err
 	}
-	c.vals = newItems
+	c.store(newItems)
 	return nil
 }
 
-// Set 设置 CopyOnWriteArrayList 里 index 位置的值为 t
-func (c *CopyOnWriteArrayList[T]) Set(index int, t T) error {
+// AddIfAbsent 当 CopyOnWriteArrayList 中没有和 val 相等的元素时追加 val，返回是否追加了
+// 检查和追加在同一个写锁内完成，所以并发调用的时候也不会重复添加
+func (c *CopyOnWriteArrayList[T]) AddIfAbsent(val T, equal func(src, dst T) bool) bool {
+	// 先用快照检查，已经存在的时候不需要加锁
+	if c.contains(c.load(), val, equal) {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	vals := c.load()
+	if c.contains(vals, val, equal) {
+		return false
+	}
+	newItems := make([]T, 0, len(vals)+1)
+	newItems = append(newItems, vals...)
+	c.store(append(newItems, val))
+	return true
+}
+
+// AddAllAbsent 按顺序追加 src 中不存在于 CopyOnWriteArrayList 的元素，src 中重复的元素只追加第一个
+// 返回追加的元素个数，所有元素在一次写操作里面追加
+func (c *CopyOnWriteArrayList[T]) AddAllAbsent(src []T, equal func(src, dst T) bool) int {
+	if len(src) == 0 {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	vals := c.load()
+	newItems := make([]T, 0, len(vals)+len(src))
+	newItems = append(newItems, vals...)
+	for _, val := range src {
+		if !c.contains(newItems, val, equal) {
+			newItems = append(newItems, val)
+		}
+	}
+	added := len(newItems) - len(vals)
+	if added > 0 {
+		c.store(newItems)
+	}
+	return added
+}
+
+// Set 设置 CopyOnWriteArrayList 里 index 位置的值为 val
+func (c *CopyOnWriteArrayList[T]) Set(val T, index int) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	length := c.Len()
+	vals := c.load()
+	length := len(vals)
 	if index < 0 || index >= length {
-		return errs.NewErrIndexOutOfRange(length, index)
+		return errs.NewErrIndexOutOfRange(length-1, index)
 	}
-	//newItems := make([]T, length)
-	//copy(newItems, c.vals)
-	newItems := slices.Clone(c.vals)
-	newItems[index] = t
-	c.vals = newItems
+	newItems := slices.Clone(vals)
+	newItems[index] = val
+	c.store(newItems)
 	return nil
 }
 
 // Delete 这里不涉及缩容，每次都是当前内容长度申请的数组容量
-func (c *CopyOnWriteArrayList[T]) Delete(index int) (err error) {
+func (c *CopyOnWriteArrayList[T]) Delete(index int) (T, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	length := c.Len()
+	vals := c.load()
+	length := len(vals)
 	if index < 0 || index >= length {
-		return errs.NewErrIndexOutOfRange(length-1, index)
+		var t T
+		return t, errs.NewErrIndexOutOfRange(length-1, index)
+	}
+	newItems, t, err := slice.Delete(slices.Clone(vals), index)
+	if err != nil {
+		return t, err
 	}
-	newItems := slices.Clone(c.vals)
-	newItems, _, err = slice.Delete(newItems, index)
-	c.vals = newItems
-	return err
+	c.store(newItems)
+	return t, nil
 }
 
 func (c *CopyOnWriteArrayList[T]) Len() int {
-	return len(c.vals)
+	return len(c.load())
 }
 
 func (c *CopyOnWriteArrayList[T]) Cap() int {
-	return cap(c.vals)
+	return cap(c.load())
 }
 
+// AsSlice 复制当前快照，不需要加锁
 func (c *CopyOnWriteArrayList[T]) AsSlice() []T {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	res := slices.Clone(c.vals)
-	return res
+	return slices.Clone(c.load())
+}
+
+// All 遍历开始时的快照，遍历过程中的写操作不会影响本次遍历，也不会被阻塞
+func (c *CopyOnWriteArrayList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range c.load() {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+func (c *CopyOnWriteArrayList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range c.load() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (c *CopyOnWriteArrayList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		vals := c.load()
+		for i := len(vals) - 1; i >= 0; i-- {
+			if !yield(i, vals[i]) {
+				return
+			}
+		}
+	}
+}
+
+// load 返回当前的快照，写操作每次都会生成新的切片，所以快照不会再变化，调用方不能修改它
+func (c *CopyOnWriteArrayList[T]) load() []T {
+	vals := c.vals.Load()
+	if vals == nil {
+		// 零值的 CopyOnWriteArrayList
+		return nil
+	}
+	return *vals
+}
+
+func (c *CopyOnWriteArrayList[T]) store(vals []T) {
+	c.vals.Store(&vals)
+}
+
+func (c *CopyOnWriteArrayList[T]) contains(vals []T, val T, equal func(src, dst T) bool) bool {
+	for _, v := range vals {
+		if equal(v, val) {
+			return true
+		}
+	}
+	return false
 }
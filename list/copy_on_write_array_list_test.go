@@ -3,6 +3,9 @@ package list
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/zmsocc/generic/internal/errs"
+	"golang.org/x/exp/slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -286,3 +289,187 @@ func TestCopyOnWriteArrayList_Cap(t *testing.T) {
 //	// 但是地址不同，也就是意味着 slice 必须是一个新创建的
 //	assert.Equal(t, aAddr, sliceAddr)
 //}
+
+func TestCopyOnWriteArrayList_Iter(t *testing.T) {
+	testIter(t, NewCopyOnWriteArrayListOf[int]([]int{1, 2, 3}), []int{1, 2, 3})
+	testIter(t, NewCopyOnWriteArrayList[int](), []int{})
+
+	// 遍历的是快照，遍历过程中的修改不会影响本次遍历
+	l := NewCopyOnWriteArrayListOf[int]([]int{1, 2, 3})
+	vals := make([]int, 0, 3)
+	for i, v := range l.All() {
+		assert.NoError(t, l.Set(v*10, i))
+		assert.NoError(t, l.Append(v))
+		vals = append(vals, v)
+	}
+	assert.Equal(t, []int{1, 2, 3}, vals)
+	assert.Equal(t, []int{10, 20, 30, 1, 2, 3}, l.AsSlice())
+}
+
+func TestCopyOnWriteArrayList_AddIfAbsent(t *testing.T) {
+	equal := func(src, dst int) bool { return src == dst }
+	testCases := []struct {
+		name      string
+		src       []int
+		val       int
+		wantAdded bool
+		wantSlice []int
+	}{
+		{
+			name:      "absent",
+			src:       []int{1, 2, 3},
+			val:       4,
+			wantAdded: true,
+			wantSlice: []int{1, 2, 3, 4},
+		},
+		{
+			name:      "present",
+			src:       []int{1, 2, 3},
+			val:       2,
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name:      "empty",
+			val:       1,
+			wantAdded: true,
+			wantSlice: []int{1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewCopyOnWriteArrayListOf[int](tc.src)
+			assert.Equal(t, tc.wantAdded, l.AddIfAbsent(tc.val, equal))
+			assert.Equal(t, tc.wantSlice, l.AsSlice())
+		})
+	}
+}
+
+func TestCopyOnWriteArrayList_AddAllAbsent(t *testing.T) {
+	equal := func(src, dst int) bool { return src == dst }
+	testCases := []struct {
+		name      string
+		src       []int
+		vals      []int
+		wantAdded int
+		wantSlice []int
+	}{
+		{
+			// src 中重复的元素只追加第一个
+			name:      "partly absent",
+			src:       []int{1, 2, 3},
+			vals:      []int{4, 2, 5, 4},
+			wantAdded: 2,
+			wantSlice: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:      "all present",
+			src:       []int{1, 2, 3},
+			vals:      []int{3, 1},
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name:      "nil",
+			src:       []int{1},
+			wantSlice: []int{1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewCopyOnWriteArrayListOf[int](tc.src)
+			assert.Equal(t, tc.wantAdded, l.AddAllAbsent(tc.vals, equal))
+			assert.Equal(t, tc.wantSlice, l.AsSlice())
+		})
+	}
+}
+
+func TestCopyOnWriteArrayList_ZeroValue(t *testing.T) {
+	var l CopyOnWriteArrayList[int]
+	assert.Equal(t, 0, l.Len())
+	_, err := l.Get(0)
+	assert.Equal(t, errs.NewErrIndexOutOfRange(-1, 0), err)
+	assert.NoError(t, l.Append(1, 2))
+	assert.Equal(t, []int{1, 2}, l.AsSlice())
+}
+
+// 读写并发的时候，读到的每一个快照都是某一次写操作完成之后的完整状态
+// 写操作每次成对地追加 v 和 -v，所以任何一个快照里面的元素都必须是成对的
+func TestCopyOnWriteArrayList_ConcurrentSnapshot(t *testing.T) {
+	l := NewCopyOnWriteArrayList[int]()
+	var writers, readers sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		writers.Add(1)
+		go func(start int) {
+			defer writers.Done()
+			for j := 1; j <= 500; j++ {
+				v := start*1000 + j
+				assert.NoError(t, l.Append(v, -v))
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				var prev int
+				cnt := 0
+				for idx, v := range l.All() {
+					if idx%2 == 1 {
+						assert.Equal(t, -prev, v)
+					}
+					prev = v
+					cnt++
+				}
+				assert.Equal(t, 0, cnt%2)
+				vals := l.AsSlice()
+				assert.Equal(t, 0, len(vals)%2)
+				if n := l.Len(); n > 0 {
+					_, err := l.Get(n - 1)
+					assert.NoError(t, err)
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	close(done)
+	readers.Wait()
+	assert.Equal(t, 4*500*2, l.Len())
+}
+
+// 并发地 AddIfAbsent 和 AddAllAbsent 同一批元素，每个元素只会被添加一次
+func TestCopyOnWriteArrayList_ConcurrentAddIfAbsent(t *testing.T) {
+	equal := func(src, dst int) bool { return src == dst }
+	l := NewCopyOnWriteArrayList[int]()
+	var wg sync.WaitGroup
+	var added atomic.Int64
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if i%2 == 0 {
+					if l.AddIfAbsent(j, equal) {
+						added.Add(1)
+					}
+					continue
+				}
+				added.Add(int64(l.AddAllAbsent([]int{j, j + 1}, equal)))
+			}
+		}(i)
+	}
+	wg.Wait()
+	res := l.AsSlice()
+	slices.Sort(res)
+	want := make([]int, 0, 201)
+	for i := 0; i <= 200; i++ {
+		want = append(want, i)
+	}
+	assert.Equal(t, want, res)
+	assert.Equal(t, int64(201), added.Load())
+}
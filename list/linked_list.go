@@ -2,8 +2,11 @@ package list
 
 import (
 	"github.com/zmsocc/generic/internal/errs"
+	"iter"
 )
 
+var _ List[any] = &LinkedList[any]{}
+
 // node 双向循环链表结点
 type node[T any] struct {
 	prev *node[T]
@@ -102,10 +105,11 @@ func (l *LinkedList[T]) Set(val T, index int) error {
 	return nil
 }
 
-// Delete 删除 LinkedList 下标在 index 处的值
-func (l *LinkedList[T]) Delete(index int) error {
+// Delete 删除 LinkedList 下标在 index 处的值，并返回被删除的值
+func (l *LinkedList[T]) Delete(index int) (T, error) {
 	if !l.checkIndex(index) {
-		return errs.NewErrIndexOutOfRange(l.length-1, index)
+		var t T
+		return t, errs.NewErrIndexOutOfRange(l.length-1, index)
 	}
 	find := l.findNode(index)
 	find.prev.next = find.next
@@ -113,8 +117,11 @@ func (l *LinkedList[T]) Delete(index int) error {
 	if index == 0 {
 		l.head = find.next
 	}
+	if index == l.length-1 {
+		l.tail = find.prev
+	}
 	l.length--
-	return nil
+	return find.val, nil
 }
 
 // Len 返回链表长度
@@ -141,6 +148,42 @@ func (l *LinkedList[T]) AsSlice() []T {
 	return slice
 }
 
+// All 沿着结点遍历，每一步都是 O(1) 的，遍历过程中不应修改链表
+func (l *LinkedList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		cur := l.head
+		for i := 0; i < l.length; i++ {
+			if !yield(i, cur.val) {
+				return
+			}
+			cur = cur.next
+		}
+	}
+}
+
+func (l *LinkedList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range l.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 从 tail 沿着 prev 往前遍历
+func (l *LinkedList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		cur := l.tail
+		for i := l.length - 1; i >= 0; i-- {
+			if !yield(i, cur.val) {
+				return
+			}
+			cur = cur.prev
+		}
+	}
+}
+
 func (l *LinkedList[T]) findNode(index int) *node[T] {
 	var cur *node[T]
 	if index < l.length/2 {
@@ -240,6 +240,7 @@ func TestLinkedList_Delete(t *testing.T) {
 		name    string
 		list    *LinkedList[int]
 		index   int
+		wantVal int
 		wantRes *LinkedList[int]
 		wantErr error
 	}{
@@ -259,29 +260,45 @@ func TestLinkedList_Delete(t *testing.T) {
 			name:    "delete existent index",
 			list:    NewLinkedListOf[int]([]int{1, 2, 3}),
 			index:   0,
+			wantVal: 1,
 			wantRes: NewLinkedListOf[int]([]int{2, 3}),
 		},
 		{
 			name:    "delete existent index 2",
 			list:    NewLinkedListOf[int]([]int{1, 2, 3}),
 			index:   1,
+			wantVal: 2,
 			wantRes: NewLinkedListOf[int]([]int{1, 3}),
 		},
 		{
 			name:    "delete existent index 3",
 			list:    NewLinkedListOf[int]([]int{1, 2, 3}),
 			index:   2,
+			wantVal: 3,
 			wantRes: NewLinkedListOf[int]([]int{1, 2}),
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.list.Delete(tc.index)
+			val, err := tc.list.Delete(tc.index)
 			assert.Equal(t, tc.wantErr, err)
 			if err != nil {
 				return
 			}
+			assert.Equal(t, tc.wantVal, val)
 			assert.Equal(t, tc.wantRes.AsSlice(), tc.list.AsSlice())
 		})
 	}
 }
+
+func TestLinkedList_Iter(t *testing.T) {
+	testIter(t, NewLinkedListOf[int]([]int{1, 2, 3, 4, 5}), []int{1, 2, 3, 4, 5})
+	testIter(t, NewLinkedList[int](), []int{})
+
+	// Add 和 Delete 之后依旧能正确遍历
+	l := NewLinkedListOf[int]([]int{1, 2, 3})
+	assert.NoError(t, l.Add(0, 0))
+	_, err := l.Delete(2)
+	assert.NoError(t, err)
+	testIter(t, l, []int{0, 1, 3})
+}
@@ -3,8 +3,12 @@ package list
 import (
 	"github.com/zmsocc/generic"
 	"github.com/zmsocc/generic/internal/list"
+	"iter"
 )
 
+// SkipListCursor 跳表上的双向游标，由 SkipList 的 Seek、First 和 Last 创建
+type SkipListCursor[T any] = list.SkipListCursor[T]
+
 type SkipList[T any] struct {
 	skiplist *list.SkipList[T]
 }
@@ -19,6 +23,21 @@ func (l *SkipList[T]) Get(index int) (T, error) {
 	return l.skiplist.Get(index)
 }
 
+// Rank 返回第一个和 val 相等的元素的下标，不存在的时候返回 -1
+func (l *SkipList[T]) Rank(val T) int {
+	return l.skiplist.Rank(val)
+}
+
+// DeleteAt 删除下标为 index 的元素并返回
+func (l *SkipList[T]) DeleteAt(index int) (T, error) {
+	return l.skiplist.DeleteAt(index)
+}
+
+// Range 返回下标在 [from, to) 之间的元素
+func (l *SkipList[T]) Range(from, to int) ([]T, error) {
+	return l.skiplist.Range(from, to)
+}
+
 func (l *SkipList[T]) Search(val T) bool {
 	return l.skiplist.Search(val)
 }
@@ -42,3 +61,70 @@ func (l *SkipList[T]) Cap() int {
 func (l *SkipList[T]) AsSlice() []T {
 	return l.skiplist.AsSlice()
 }
+
+func (l *SkipList[T]) All() iter.Seq2[int, T] {
+	return l.skiplist.All()
+}
+
+func (l *SkipList[T]) Values() iter.Seq[T] {
+	return l.skiplist.Values()
+}
+
+func (l *SkipList[T]) Backward() iter.Seq2[int, T] {
+	return l.skiplist.Backward()
+}
+
+// Min 返回最小的元素，SkipList 为空的时候返回 false
+func (l *SkipList[T]) Min() (T, bool) {
+	return l.skiplist.Min()
+}
+
+// Max 返回最大的元素，SkipList 为空的时候返回 false
+func (l *SkipList[T]) Max() (T, bool) {
+	return l.skiplist.Max()
+}
+
+// Floor 返回小于等于 val 的最大元素
+func (l *SkipList[T]) Floor(val T) (T, bool) {
+	return l.skiplist.Floor(val)
+}
+
+// Lower 返回严格小于 val 的最大元素
+func (l *SkipList[T]) Lower(val T) (T, bool) {
+	return l.skiplist.Lower(val)
+}
+
+// Ceiling 返回大于等于 val 的最小元素
+func (l *SkipList[T]) Ceiling(val T) (T, bool) {
+	return l.skiplist.Ceiling(val)
+}
+
+// Higher 返回严格大于 val 的最小元素
+func (l *SkipList[T]) Higher(val T) (T, bool) {
+	return l.skiplist.Higher(val)
+}
+
+// RangeBetween 从小到大遍历在 lo 和 hi 之间的元素，loInclusive 和 hiInclusive 表示是否包含边界
+func (l *SkipList[T]) RangeBetween(lo, hi T, loInclusive, hiInclusive bool) iter.Seq[T] {
+	return l.skiplist.RangeBetween(lo, hi, loInclusive, hiInclusive)
+}
+
+// DeleteRange 删除在 lo 和 hi 之间的所有元素，返回删除的个数
+func (l *SkipList[T]) DeleteRange(lo, hi T, loInclusive, hiInclusive bool) int {
+	return l.skiplist.DeleteRange(lo, hi, loInclusive, hiInclusive)
+}
+
+// Seek 返回指向第一个大于等于 val 的元素的游标
+func (l *SkipList[T]) Seek(val T) *SkipListCursor[T] {
+	return l.skiplist.Seek(val)
+}
+
+// First 返回指向最小元素的游标
+func (l *SkipList[T]) First() *SkipListCursor[T] {
+	return l.skiplist.First()
+}
+
+// Last 返回指向最大元素的游标
+func (l *SkipList[T]) Last() *SkipListCursor[T] {
+	return l.skiplist.Last()
+}
@@ -171,3 +171,67 @@ func ExampleNewSkipList() {
 	// output:
 	// 123
 }
+
+func TestSkipList_Iter(t *testing.T) {
+	l := NewSkipList[int](generic.ComparatorOrdered[int])
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		l.Insert(v)
+	}
+	testIter(t, l, []int{1, 2, 3, 4, 5})
+	testIter(t, NewSkipList[int](generic.ComparatorOrdered[int]), []int{})
+}
+
+func TestSkipList_Rank(t *testing.T) {
+	// 按照分数从高到低排序的排行榜
+	l := NewSkipList[int](generic.Reverse(generic.ComparatorOrdered[int]))
+	for _, score := range []int{80, 95, 60, 95, 70} {
+		l.Insert(score)
+	}
+	assert.Equal(t, 0, l.Rank(95))
+	assert.Equal(t, 2, l.Rank(80))
+	assert.Equal(t, 4, l.Rank(60))
+	assert.Equal(t, -1, l.Rank(100))
+
+	res, err := l.Range(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{95, 95, 80}, res)
+
+	val, err := l.DeleteAt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 95, val)
+	assert.Equal(t, 1, l.Rank(80))
+	_, err = l.DeleteAt(4)
+	assert.Error(t, err)
+}
+
+func TestSkipList_RangeBetween(t *testing.T) {
+	l := NewSkipList[int](generic.ComparatorOrdered[int])
+	for _, v := range []int{50, 10, 30, 20, 40} {
+		l.Insert(v)
+	}
+	floor, ok := l.Floor(35)
+	assert.True(t, ok)
+	assert.Equal(t, 30, floor)
+	ceiling, ok := l.Ceiling(35)
+	assert.True(t, ok)
+	assert.Equal(t, 40, ceiling)
+
+	res := make([]int, 0, 3)
+	for v := range l.RangeBetween(20, 40, true, false) {
+		res = append(res, v)
+	}
+	assert.Equal(t, []int{20, 30}, res)
+
+	res = res[:0]
+	for c := l.Seek(35); c.Valid(); c.Prev() {
+		res = append(res, c.Value())
+	}
+	assert.Equal(t, []int{40, 30, 20, 10}, res)
+
+	assert.Equal(t, 2, l.DeleteRange(20, 40, false, true))
+	assert.Equal(t, []int{10, 20, 50}, l.AsSlice())
+	minVal, _ := l.Min()
+	maxVal, _ := l.Max()
+	assert.Equal(t, 10, minVal)
+	assert.Equal(t, 50, maxVal)
+}
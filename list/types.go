@@ -1,5 +1,7 @@
 package list
 
+import "iter"
+
 // List 接口
 // 该接口只定义清楚各个方法的行为和表现
 type List[T any] interface {
@@ -19,4 +21,10 @@ type List[T any] interface {
 	Len() int
 	// AsSlice 将 List 转化为一个切片
 	AsSlice() []T
+	// All 从前往后遍历，返回下标和元素
+	All() iter.Seq2[int, T]
+	// Values 从前往后遍历，只返回元素
+	Values() iter.Seq[T]
+	// Backward 从后往前遍历，返回下标和元素
+	Backward() iter.Seq2[int, T]
 }
@@ -0,0 +1,218 @@
+package list
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic/internal/errs"
+	"golang.org/x/exp/slices"
+	"iter"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// listSuite List 的一致性测试用例
+type listSuite struct {
+	name string
+	// newList 用 src 初始化一个 List，不能修改 src
+	newList func(src []int) List[int]
+	// concurrent 为 true 的时候额外做并发测试
+	concurrent bool
+}
+
+func listSuites() []listSuite {
+	suites := []listSuite{
+		{
+			name: "ArrayList",
+			newList: func(src []int) List[int] {
+				return NewArrayListOf[int](slices.Clone(src))
+			},
+		},
+		{
+			name: "LinkedList",
+			newList: func(src []int) List[int] {
+				return NewLinkedListOf[int](src)
+			},
+		},
+		{
+			name: "CopyOnWriteArrayList",
+			newList: func(src []int) List[int] {
+				return NewCopyOnWriteArrayListOf[int](src)
+			},
+			concurrent: true,
+		},
+	}
+	// ConcurrentList 可以包装任意一个 List
+	for _, s := range suites[:3] {
+		newList := s.newList
+		suites = append(suites, listSuite{
+			name: "ConcurrentList of " + s.name,
+			newList: func(src []int) List[int] {
+				return &ConcurrentList[int]{List: newList(src)}
+			},
+			concurrent: true,
+		})
+	}
+	return suites
+}
+
+func TestListConformance(t *testing.T) {
+	for _, s := range listSuites() {
+		t.Run(s.name, func(t *testing.T) {
+			testList(t, s)
+		})
+	}
+}
+
+func testList(t *testing.T, s listSuite) {
+	t.Run("index out of range", func(t *testing.T) {
+		l := s.newList([]int{1, 2, 3})
+		_, err := l.Get(3)
+		assert.Equal(t, errs.NewErrIndexOutOfRange(2, 3), err)
+		_, err = l.Get(-1)
+		assert.Equal(t, errs.NewErrIndexOutOfRange(2, -1), err)
+		assert.Equal(t, errs.NewErrIndexOutOfRange(2, 3), l.Set(4, 3))
+		assert.Equal(t, errs.NewErrIndexOutOfRange(2, 4), l.Add(4, 4))
+		_, err = l.Delete(3)
+		assert.Equal(t, errs.NewErrIndexOutOfRange(2, 3), err)
+		assert.Equal(t, []int{1, 2, 3}, l.AsSlice())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		l := s.newList(nil)
+		assert.Equal(t, 0, l.Len())
+		assert.Empty(t, l.AsSlice())
+		_, err := l.Delete(0)
+		assert.Equal(t, errs.NewErrIndexOutOfRange(-1, 0), err)
+		testIter(t, l, []int{})
+	})
+
+	// 随机操作，和切片的结果对比
+	t.Run("random", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		want := []int{1, 2, 3}
+		l := s.newList(want)
+		for i := 0; i < 2000; i++ {
+			switch op := r.Intn(5); {
+			case op == 0:
+				require.NoError(t, l.Append(i, i+1))
+				want = append(want, i, i+1)
+			case op == 1:
+				idx := r.Intn(len(want) + 1)
+				require.NoError(t, l.Add(i, idx))
+				want = slices.Insert(want, idx, i)
+			case op == 2 && len(want) > 0:
+				idx := r.Intn(len(want))
+				require.NoError(t, l.Set(i, idx))
+				want[idx] = i
+			case len(want) > 0:
+				idx := r.Intn(len(want))
+				val, err := l.Delete(idx)
+				require.NoError(t, err)
+				assert.Equal(t, want[idx], val)
+				want = slices.Delete(want, idx, idx+1)
+			}
+			require.Equal(t, len(want), l.Len())
+			require.GreaterOrEqual(t, l.Cap(), l.Len())
+			if len(want) > 0 {
+				idx := r.Intn(len(want))
+				val, err := l.Get(idx)
+				require.NoError(t, err)
+				require.Equal(t, want[idx], val)
+			}
+		}
+		assert.Equal(t, want, append([]int{}, l.AsSlice()...))
+		testIter(t, l, want)
+	})
+
+	t.Run("as slice", func(t *testing.T) {
+		// 修改 AsSlice 的结果不会影响 List
+		l := s.newList([]int{1, 2, 3})
+		res := l.AsSlice()
+		res[0] = 100
+		assert.Equal(t, []int{1, 2, 3}, l.AsSlice())
+	})
+
+	if !s.concurrent {
+		return
+	}
+	t.Run("concurrent", func(t *testing.T) {
+		l := s.newList(nil)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(start int) {
+				defer wg.Done()
+				for j := start; j < 800; j += 8 {
+					assert.NoError(t, l.Append(j))
+					_, _ = l.Get(0)
+					for range l.Values() {
+						break
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		res := l.AsSlice()
+		slices.Sort(res)
+		want := make([]int, 0, 800)
+		for i := 0; i < 800; i++ {
+			want = append(want, i)
+		}
+		assert.Equal(t, want, res)
+	})
+}
+
+// testIter 检查 All、Values 和 Backward 的结果，以及提前 break 的时候能正常停止
+func testIter(t *testing.T, l interface {
+	All() iter.Seq2[int, int]
+	Values() iter.Seq[int]
+	Backward() iter.Seq2[int, int]
+}, want []int) {
+	idx, vals := make([]int, 0, len(want)), make([]int, 0, len(want))
+	for i, v := range l.All() {
+		idx = append(idx, i)
+		vals = append(vals, v)
+	}
+	wantIdx := make([]int, 0, len(want))
+	for i := range want {
+		wantIdx = append(wantIdx, i)
+	}
+	assert.Equal(t, wantIdx, idx)
+	assert.Equal(t, want, vals)
+
+	vals = vals[:0]
+	for v := range l.Values() {
+		vals = append(vals, v)
+	}
+	assert.Equal(t, want, vals)
+
+	idx, vals = idx[:0], vals[:0]
+	for i, v := range l.Backward() {
+		idx = append(idx, i)
+		vals = append(vals, v)
+	}
+	slices.Reverse(wantIdx)
+	wantVals := slices.Clone(want)
+	slices.Reverse(wantVals)
+	assert.Equal(t, wantIdx, idx)
+	assert.Equal(t, wantVals, vals)
+
+	if len(want) == 0 {
+		return
+	}
+	cnt := 0
+	for range l.All() {
+		cnt++
+		break
+	}
+	for range l.Values() {
+		cnt++
+		break
+	}
+	for range l.Backward() {
+		cnt++
+		break
+	}
+	assert.Equal(t, 3, cnt)
+}
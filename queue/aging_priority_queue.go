@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"context"
+	"github.com/zmsocc/generic/internal/queue"
+	"sync"
+	"time"
+)
+
+var _ BlockingQueue[any] = &AgingPriorityQueue[any]{}
+
+// AgingFunc 计算元素等待了 waited 之后的有效优先级，值越小越先出队
+// 对于同一个元素，waited 越大，返回值应当越小，否则低优先级的元素依旧可能饿死
+type AgingFunc[T any] func(t T, waited time.Duration) int64
+
+// LinearAging 线性老化：初始优先级为 priority(t)，每等待 step，有效优先级减 1
+// 优先级相差 k 的两个元素，低优先级的元素最多比高优先级的元素多等待 k * step
+func LinearAging[T any](priority func(t T) int64, step time.Duration) AgingFunc[T] {
+	return func(t T, waited time.Duration) int64 {
+		return priority(t) - int64(waited/step)
+	}
+}
+
+type agingElem[T any] struct {
+	val        T
+	enqueuedAt time.Time
+	seq        uint64 // 入队顺序，有效优先级相同的时候先入队的先出队
+	priority   int64  // 上一次刷新时计算的有效优先级
+}
+
+// AgingPriorityQueue 带老化的并发阻塞优先级队列
+// 元素的有效优先级由 AgingFunc 根据等待时间计算，等待越久优先级越高，
+// 从而避免在高优先级元素源源不断的情况下，低优先级的元素永远出不了队
+// 有界的时候，队列满了入队会阻塞；队列为空的时候出队会阻塞
+//
+// 有效优先级不是实时计算的：出队的时候，如果距离上一次刷新超过了 refresh，
+// 才会用同一个时间点重新计算所有元素的有效优先级并重新建堆，时间复杂度为 O(n)
+type AgingPriorityQueue[T any] struct {
+	q             *queue.PriorityQueue[agingElem[T]]
+	aging         AgingFunc[T]
+	refresh       time.Duration
+	clock         Clock
+	lastRefresh   time.Time
+	seq           uint64
+	mutex         *sync.Mutex
+	enqueueSignal *cond
+	dequeueSignal *cond
+	zero          T
+}
+
+// NewAgingPriorityQueue 创建一个带老化的并发阻塞优先级队列
+// capacity <= 0 表示无界队列
+// refresh 是重新计算有效优先级的最小间隔，<= 0 的时候每次出队都会重新计算
+func NewAgingPriorityQueue[T any](capacity int, aging AgingFunc[T], refresh time.Duration) *AgingPriorityQueue[T] {
+	return NewAgingPriorityQueueWithClock[T](capacity, aging, refresh, realClock{})
+}
+
+// NewAgingPriorityQueueWithClock 使用指定的时间源创建一个带老化的并发阻塞优先级队列
+func NewAgingPriorityQueueWithClock[T any](capacity int, aging AgingFunc[T], refresh time.Duration, clock Clock) *AgingPriorityQueue[T] {
+	m := &sync.Mutex{}
+	return &AgingPriorityQueue[T]{
+		q:             queue.NewPriorityQueue[agingElem[T]](capacity, compareAgingElem[T]),
+		aging:         aging,
+		refresh:       refresh,
+		clock:         clock,
+		lastRefresh:   clock.Now(),
+		mutex:         m,
+		enqueueSignal: newCond(m),
+		dequeueSignal: newCond(m),
+	}
+}
+
+// Enqueue 入队
+// 队列满的时候会阻塞，直到有元素出队或者 ctx 过期
+func (a *AgingPriorityQueue[T]) Enqueue(ctx context.Context, t T) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		a.mutex.Lock()
+		err := a.enqueue(t)
+		if err == nil {
+			a.enqueueSignal.broadcast()
+			return nil
+		}
+		// 队列已满，等待出队
+		signal := a.dequeueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// Dequeue 取出有效优先级最高的元素
+// 队列为空的时候会阻塞，直到有元素入队或者 ctx 过期
+func (a *AgingPriorityQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		if ctx.Err() != nil {
+			return a.zero, ctx.Err()
+		}
+		a.mutex.Lock()
+		val, err := a.dequeue()
+		if err == nil {
+			a.dequeueSignal.broadcast()
+			return val, nil
+		}
+		// 队列为空，等待入队
+		signal := a.enqueueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return a.zero, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// TryEnqueue 非阻塞入队，队列满的时候返回 ErrOutOfCapacity
+func (a *AgingPriorityQueue[T]) TryEnqueue(t T) error {
+	a.mutex.Lock()
+	err := a.enqueue(t)
+	if err != nil {
+		a.mutex.Unlock()
+		return err
+	}
+	a.enqueueSignal.broadcast()
+	return nil
+}
+
+// TryDequeue 非阻塞出队，队列为空的时候返回 ErrEmptyQueue
+func (a *AgingPriorityQueue[T]) TryDequeue() (T, error) {
+	a.mutex.Lock()
+	val, err := a.dequeue()
+	if err != nil {
+		a.mutex.Unlock()
+		return a.zero, err
+	}
+	a.dequeueSignal.broadcast()
+	return val, nil
+}
+
+func (a *AgingPriorityQueue[T]) Len() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.q.Len()
+}
+
+// Cap 返回容量，无界队列返回 0
+func (a *AgingPriorityQueue[T]) Cap() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.q.Cap()
+}
+
+// enqueue 调用方负责加锁
+// 堆中其他元素的有效优先级是按照上一次刷新的时间点计算的，新元素在那个时间点还没有开始等待
+func (a *AgingPriorityQueue[T]) enqueue(t T) error {
+	now := a.clock.Now()
+	elem := agingElem[T]{
+		val:        t,
+		enqueuedAt: now,
+		seq:        a.seq,
+		priority:   a.aging(t, 0),
+	}
+	if err := a.q.Enqueue(elem); err != nil {
+		return err
+	}
+	a.seq++
+	return nil
+}
+
+// dequeue 调用方负责加锁
+func (a *AgingPriorityQueue[T]) dequeue() (T, error) {
+	a.refreshIfNecessary()
+	elem, err := a.q.Dequeue()
+	if err != nil {
+		return a.zero, err
+	}
+	return elem.val, nil
+}
+
+func (a *AgingPriorityQueue[T]) refreshIfNecessary() {
+	now := a.clock.Now()
+	if a.refresh > 0 && now.Sub(a.lastRefresh) < a.refresh {
+		return
+	}
+	a.lastRefresh = now
+	a.q.UpdateAll(func(elem agingElem[T]) agingElem[T] {
+		elem.priority = a.aging(elem.val, now.Sub(elem.enqueuedAt))
+		return elem
+	})
+}
+
+func compareAgingElem[T any](src, dst agingElem[T]) int {
+	switch {
+	case src.priority < dst.priority:
+		return -1
+	case src.priority > dst.priority:
+		return 1
+	case src.seq < dst.seq:
+		return -1
+	case src.seq > dst.seq:
+		return 1
+	default:
+		return 0
+	}
+}
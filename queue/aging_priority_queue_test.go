@@ -0,0 +1,165 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type agingJob struct {
+	name     string
+	priority int64
+}
+
+func agingJobPriority(j agingJob) int64 {
+	return j.priority
+}
+
+func TestAgingPriorityQueue_Order(t *testing.T) {
+	clock := newFakeClock()
+	q := NewAgingPriorityQueueWithClock[agingJob](0, LinearAging(agingJobPriority, time.Millisecond), 0, clock)
+	require.NoError(t, q.TryEnqueue(agingJob{name: "low", priority: 10}))
+	clock.Advance(time.Millisecond * 4)
+	require.NoError(t, q.TryEnqueue(agingJob{name: "high", priority: 1}))
+	require.NoError(t, q.TryEnqueue(agingJob{name: "middle", priority: 6}))
+	// low 已经等待了 4ms，有效优先级为 6，和 middle 相同，先入队的先出队
+	require.NoError(t, q.TryEnqueue(agingJob{name: "middle2", priority: 6}))
+	assert.Equal(t, 4, q.Len())
+	assert.Equal(t, 0, q.Cap())
+
+	var res []string
+	for {
+		val, err := q.TryDequeue()
+		if err != nil {
+			assert.Equal(t, ErrEmptyQueue, err)
+			break
+		}
+		res = append(res, val.name)
+	}
+	assert.Equal(t, []string{"high", "low", "middle", "middle2"}, res)
+}
+
+// 高优先级的元素源源不断地入队，低优先级的元素依旧能在有限的时间内出队
+func TestAgingPriorityQueue_BoundedWait(t *testing.T) {
+	testCases := []struct {
+		name        string
+		aging       AgingFunc[agingJob]
+		refresh     time.Duration
+		highPerTick int
+		// 低优先级元素最多等待多少次出队，-1 表示会饿死
+		maxDequeues int
+	}{
+		{
+			name:        "no aging starves",
+			aging:       func(t agingJob, _ time.Duration) int64 { return t.priority },
+			highPerTick: 1,
+			maxDequeues: -1,
+		},
+		{
+			name:        "linear aging",
+			aging:       LinearAging(agingJobPriority, time.Millisecond),
+			highPerTick: 1,
+			maxDequeues: 101,
+		},
+		{
+			// 入队比出队快，积压越来越多，但是在低优先级元素之后入队的高优先级元素，
+			// 等待 100ms 之后就排在它后面了
+			name:        "backlog",
+			aging:       LinearAging(agingJobPriority, time.Millisecond),
+			highPerTick: 2,
+			maxDequeues: 202,
+		},
+		{
+			// 有效优先级最多滞后 refresh
+			name:        "refresh interval",
+			aging:       LinearAging(agingJobPriority, time.Millisecond),
+			refresh:     time.Millisecond * 10,
+			highPerTick: 2,
+			maxDequeues: 222,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock()
+			q := NewAgingPriorityQueueWithClock[agingJob](0, tc.aging, tc.refresh, clock)
+			require.NoError(t, q.TryEnqueue(agingJob{name: "low", priority: 100}))
+			dequeues := 0
+			for ; dequeues < 2000; dequeues++ {
+				for i := 0; i < tc.highPerTick; i++ {
+					require.NoError(t, q.TryEnqueue(agingJob{name: "high", priority: 0}))
+				}
+				clock.Advance(time.Millisecond)
+				val, err := q.TryDequeue()
+				require.NoError(t, err)
+				if val.name == "low" {
+					break
+				}
+			}
+			if tc.maxDequeues < 0 {
+				assert.Equal(t, 2000, dequeues)
+				return
+			}
+			assert.LessOrEqual(t, dequeues, tc.maxDequeues)
+		})
+	}
+}
+
+// 使用真实的时间，多个生产者不停地放入高优先级任务
+func TestAgingPriorityQueue_BoundedWaitConcurrent(t *testing.T) {
+	q := NewAgingPriorityQueue[agingJob](64, LinearAging(agingJobPriority, time.Millisecond), time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q.Enqueue(ctx, agingJob{name: "high"}) == nil {
+			}
+		}()
+	}
+	require.NoError(t, q.Enqueue(context.Background(), agingJob{name: "low", priority: 20}))
+	start := time.Now()
+	deadline, stop := context.WithTimeout(context.Background(), time.Second*5)
+	defer stop()
+	for {
+		val, err := q.Dequeue(deadline)
+		require.NoError(t, err, "低优先级的任务饿死了")
+		if val.name == "low" {
+			break
+		}
+	}
+	assert.Less(t, time.Since(start), time.Second*5)
+	cancel()
+	wg.Wait()
+}
+
+func TestAgingPriorityQueue_Blocking(t *testing.T) {
+	q := NewAgingPriorityQueue[agingJob](1, LinearAging(agingJobPriority, time.Millisecond), 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	require.NoError(t, q.Enqueue(context.Background(), agingJob{name: "a"}))
+	assert.Equal(t, ErrOutOfCapacity, q.TryEnqueue(agingJob{name: "b"}))
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel2()
+	assert.Equal(t, context.DeadlineExceeded, q.Enqueue(ctx2, agingJob{name: "b"}))
+
+	// 队列满的时候入队阻塞，出队之后被唤醒
+	done := make(chan error)
+	go func() {
+		done <- q.Enqueue(context.Background(), agingJob{name: "c"})
+	}()
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", val.name)
+	require.NoError(t, <-done)
+	val, err = q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "c", val.name)
+}
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec 元素的编解码方式，PersistentQueue 用它把元素写入磁盘
+type Codec[T any] interface {
+	Encode(t T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec 使用 encoding/json 编解码
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(t T) ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var t T
+	err := json.Unmarshal(data, &t)
+	return t, err
+}
+
+// GobCodec 使用 encoding/gob 编解码
+// 每个元素都是单独编码的，因此每条记录都带有完整的类型信息，可以独立解码
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Encode(t T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(t); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var t T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&t)
+	return t, err
+}
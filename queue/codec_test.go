@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type codecUser struct {
+	Name string
+	Age  int
+	Tags []string
+}
+
+func TestCodec(t *testing.T) {
+	testCases := []struct {
+		name  string
+		codec Codec[codecUser]
+	}{
+		{
+			name:  "json",
+			codec: JSONCodec[codecUser]{},
+		},
+		{
+			name:  "gob",
+			codec: GobCodec[codecUser]{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := codecUser{Name: "Tom", Age: 18, Tags: []string{"a", "b"}}
+			data, err := tc.codec.Encode(u)
+			require.NoError(t, err)
+			res, err := tc.codec.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, u, res)
+
+			_, err = tc.codec.Decode([]byte("invalid"))
+			assert.Error(t, err)
+		})
+	}
+}
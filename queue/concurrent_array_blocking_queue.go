@@ -4,8 +4,11 @@ import (
 	"context"
 	"golang.org/x/sync/semaphore"
 	"sync"
+	"sync/atomic"
 )
 
+var _ BlockingQueue[any] = &ConcurrentArrayBlockingQueue[any]{}
+
 // ConcurrentArrayBlockingQueue 有界并发阻塞队列
 type ConcurrentArrayBlockingQueue[T any] struct {
 	data       []T
@@ -16,6 +19,11 @@ type ConcurrentArrayBlockingQueue[T any] struct {
 	enqueueCap *semaphore.Weighted
 	dequeueCap *semaphore.Weighted
 	zero       T // zero 不能作为返回值返回，防止用户篡改
+	closed     bool
+	done       chan struct{}
+	// reserved 已经拿到出队信号量、但是还没拿到锁的 goroutine 个数
+	// 信号量是在加锁之前拿的，所以只能用原子操作登记，拿到锁之后马上注销
+	reserved atomic.Int64
 }
 
 // NewConcurrentArrayBlockingQueue 创建一个有界阻塞队列
@@ -32,53 +40,247 @@ func NewConcurrentArrayBlockingQueue[T any](capacity int) *ConcurrentArrayBlocki
 		mutex:      &sync.RWMutex{},
 		enqueueCap: semaForEnqueue,
 		dequeueCap: semaForDequeue,
+		done:       make(chan struct{}),
 	}
 }
 
 // Enqueue 入队
 // 通过sema来控制容量、超时、阻塞问题
+// 队列关闭之后返回 ErrQueueClosed
 func (c *ConcurrentArrayBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	select {
+	case <-c.done:
+		return ErrQueueClosed
+	default:
+	}
 	err := c.enqueueCap.Acquire(ctx, 1) // 能拿到，说明队列还有空位，可以入队，拿不到则阻塞
 	if err != nil {
 		return err
 	}
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+	if c.closed { // 在等待信号量的时候队列被关闭了，归还信号量，让其他等待的 goroutine 也能被唤醒
+		c.enqueueCap.Release(1)
+		return ErrQueueClosed
+	}
 	if ctx.Err() != nil { // 拿到锁，先判断是否超时，防止在抢锁时已经超时
 		c.enqueueCap.Release(1) // 超时应该主动归还信号量，避免容量泄露
 		return ctx.Err()
 	}
+	c.enqueue(t)
+	c.dequeueCap.Release(1) // 往出队的 sema 放入一个元素，出队的 goroutine 可以拿到并出队
+	return nil
+}
+
+// TryEnqueue 非阻塞入队，队列满的时候返回 ErrOutOfCapacity
+func (c *ConcurrentArrayBlockingQueue[T]) TryEnqueue(t T) error {
+	select {
+	case <-c.done:
+		return ErrQueueClosed
+	default:
+	}
+	if !c.enqueueCap.TryAcquire(1) {
+		return ErrOutOfCapacity
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		c.enqueueCap.Release(1)
+		return ErrQueueClosed
+	}
+	c.enqueue(t)
+	c.dequeueCap.Release(1)
+	return nil
+}
+
+// EnqueueBatch 批量入队，要么全部入队，要么一个都不入队
+// 只获取一次锁，并且一次性获取权重为 len(ts) 的信号量
+// len(ts) 超过队列容量的时候返回 ErrOutOfCapacity
+func (c *ConcurrentArrayBlockingQueue[T]) EnqueueBatch(ctx context.Context, ts ...T) error {
+	n := int64(len(ts))
+	if n == 0 {
+		return nil
+	}
+	if n > int64(cap(c.data)) { // 永远也放不下，没必要阻塞
+		return ErrOutOfCapacity
+	}
+	select {
+	case <-c.done:
+		return ErrQueueClosed
+	default:
+	}
+	err := c.enqueueCap.Acquire(ctx, n)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		c.enqueueCap.Release(n)
+		return ErrQueueClosed
+	}
+	if ctx.Err() != nil {
+		c.enqueueCap.Release(n)
+		return ctx.Err()
+	}
+	for _, t := range ts {
+		c.enqueue(t)
+	}
+	c.dequeueCap.Release(n)
+	return nil
+}
+
+// enqueue 在队尾放入元素，调用前必须持有锁，并且队列没满
+func (c *ConcurrentArrayBlockingQueue[T]) enqueue(t T) {
 	c.data[c.tail] = t
 	c.tail++
 	c.count++
 	if c.tail == cap(c.data) { // c.tail 已经是最后一个了，重置下标
 		c.tail = 0
 	}
-	c.dequeueCap.Release(1) // 往出队的 sema 放入一个元素，出队的 goroutine 可以拿到并出队
-	return nil
 }
 
+// Dequeue 出队
+// 队列关闭之后，依旧可以取出剩余的元素，取完之后返回 ErrQueueClosed
 func (c *ConcurrentArrayBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
 	err := c.dequeueCap.Acquire(ctx, 1) // 能拿到，说明队列有元素可以取，可以出队，拿不到则阻塞
 	var res T
 	if err != nil {
 		return res, err
 	}
+	c.reserved.Add(1)
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+	c.reserved.Add(-1)
 	if ctx.Err() != nil { // 拿到锁，先判断是否超时，防止在抢锁时已经超时
 		c.dequeueCap.Release(1) // 超时应该主动归还信号量，有元素消费不到
 		return res, ctx.Err()
 	}
-	res = c.data[c.head]
+	if c.closed {
+		// 关闭之后出队的信号量一直是满的，每次都要归还，保证后面的 goroutine 不会阻塞
+		defer c.dequeueCap.Release(1)
+		if c.count == 0 {
+			return res, ErrQueueClosed
+		}
+		return c.dequeue(), nil
+	}
+	res = c.dequeue()
+	c.enqueueCap.Release(1) // 往入队的 sema 放入一个元素，入队的 goroutine 可以拿到并入队
+	return res, nil
+}
+
+// TryDequeue 非阻塞出队，队列为空的时候返回 ErrEmptyQueue
+// 队列关闭并且没有剩余元素的时候返回 ErrQueueClosed
+func (c *ConcurrentArrayBlockingQueue[T]) TryDequeue() (T, error) {
+	if !c.dequeueCap.TryAcquire(1) {
+		return c.zero, ErrEmptyQueue
+	}
+	c.reserved.Add(1)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reserved.Add(-1)
+	if c.closed {
+		defer c.dequeueCap.Release(1)
+		if c.count == 0 {
+			return c.zero, ErrQueueClosed
+		}
+		return c.dequeue(), nil
+	}
+	res := c.dequeue()
+	c.enqueueCap.Release(1)
+	return res, nil
+}
+
+// DrainTo 阻塞直到至少有一个元素可以出队，然后在一次加锁内取出最多 limit 个元素，追加到 dst 后面
+// limit <= 0 表示不限制个数，取出当前所有的元素
+// 队列关闭并且没有剩余元素的时候返回 ErrQueueClosed
+func (c *ConcurrentArrayBlockingQueue[T]) DrainTo(ctx context.Context, dst []T, limit int) ([]T, error) {
+	err := c.dequeueCap.Acquire(ctx, 1) // 先阻塞等待第一个元素
+	if err != nil {
+		return dst, err
+	}
+	c.reserved.Add(1)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reserved.Add(-1)
+	if ctx.Err() != nil {
+		c.dequeueCap.Release(1)
+		return dst, ctx.Err()
+	}
+	var n int
+	if c.closed {
+		defer c.dequeueCap.Release(1)
+		n = c.count
+		if limit > 0 && limit < n {
+			n = limit
+		}
+		if n == 0 {
+			return dst, ErrQueueClosed
+		}
+	} else {
+		// 其他出队的 goroutine 已经拿到了信号量但是还没拿到锁，这部分元素要留给它们
+		// 剩下的元素加上自己已经拿到的这一个，就是可以取走的个数
+		n = c.count - int(c.reserved.Load())
+		if limit > 0 && limit < n {
+			n = limit
+		}
+		// 剩下的 n - 1 个元素一次性拿信号量
+		// 只有别的 goroutine 刚拿到信号量、还没来得及登记的时候才会失败，这个时候只取自己那一个
+		if n > 1 && !c.dequeueCap.TryAcquire(int64(n-1)) {
+			n = 1
+		}
+	}
+	for i := 0; i < n; i++ {
+		dst = append(dst, c.dequeue())
+	}
+	if !c.closed {
+		c.enqueueCap.Release(int64(n))
+	}
+	return dst, nil
+}
+
+// Peek 返回队头元素但不出队，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentArrayBlockingQueue[T]) Peek() (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.count == 0 {
+		return c.zero, ErrEmptyQueue
+	}
+	return c.data[c.head], nil
+}
+
+// dequeue 取出队头元素，调用前必须持有锁，并且队列不为空
+func (c *ConcurrentArrayBlockingQueue[T]) dequeue() T {
+	res := c.data[c.head]
 	c.data[c.head] = c.zero // 为了释放内存，GC
 	c.head++
 	c.count--
 	if c.head == cap(c.data) {
 		c.head = 0
 	}
-	c.enqueueCap.Release(1) // 往入队的 sema 放入一个元素，入队的 goroutine 可以拿到并入队
-	return res, nil
+	return res
+}
+
+// Close 关闭队列，重复关闭不会有任何效果
+// 关闭之后入队返回 ErrQueueClosed，出队会先取完剩余的元素，再返回 ErrQueueClosed
+// 阻塞在入队或者出队上的 goroutine 都会被唤醒
+func (c *ConcurrentArrayBlockingQueue[T]) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.done)
+	// 把两个信号量都归还满，之后入队和出队都不会再阻塞在信号量上
+	capacity := int64(cap(c.data))
+	c.enqueueCap.Release(int64(c.count))
+	c.dequeueCap.Release(capacity - int64(c.count))
+}
+
+// Done 返回一个 channel，队列关闭的时候该 channel 会被关闭
+func (c *ConcurrentArrayBlockingQueue[T]) Done() <-chan struct{} {
+	return c.done
 }
 
 func (c *ConcurrentArrayBlockingQueue[T]) Len() int {
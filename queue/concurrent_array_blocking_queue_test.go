@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -193,3 +195,416 @@ func TestConcurrentArrayBlockingQueue_Enqueue(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestConcurrentArrayBlockingQueue_Close(t *testing.T) {
+	t.Run("enqueue after close", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		q.Close()
+		err := q.Enqueue(context.Background(), 123)
+		assert.Equal(t, ErrQueueClosed, err)
+		assert.Equal(t, 0, q.Len())
+		select {
+		case <-q.Done():
+		default:
+			assert.Fail(t, "Done 没有被关闭")
+		}
+		// 重复关闭
+		q.Close()
+	})
+	t.Run("dequeue drains then closed", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		q.Close()
+		for i := 1; i <= 3; i++ {
+			val, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, i, val)
+		}
+		for i := 0; i < 5; i++ {
+			_, err := q.Dequeue(ctx)
+			assert.Equal(t, ErrQueueClosed, err)
+		}
+	})
+	t.Run("close wakes blocked dequeue", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := q.Dequeue(ctx)
+				assert.Equal(t, ErrQueueClosed, err)
+			}()
+		}
+		time.Sleep(time.Millisecond * 100)
+		q.Close()
+		wg.Wait()
+	})
+	t.Run("close wakes blocked enqueue", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, 1))
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := q.Enqueue(ctx, 2)
+				assert.Equal(t, ErrQueueClosed, err)
+			}()
+		}
+		time.Sleep(time.Millisecond * 100)
+		q.Close()
+		wg.Wait()
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, val)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+	// 生产者消费者并发，关闭之后所有消费者都能退出，并且不会丢失元素
+	t.Run("pipeline shutdown", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](4)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		var consumed atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					_, err := q.Dequeue(ctx)
+					if err == ErrQueueClosed {
+						return
+					}
+					assert.NoError(t, err)
+					consumed.Add(1)
+				}
+			}()
+		}
+		for i := 0; i < 100; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		q.Close()
+		wg.Wait()
+		assert.Equal(t, int64(100), consumed.Load())
+	})
+}
+
+func TestConcurrentArrayBlockingQueue_Try(t *testing.T) {
+	q := NewConcurrentArrayBlockingQueue[int](2)
+	_, err := q.TryDequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = q.Peek()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	require.NoError(t, q.TryEnqueue(1))
+	require.NoError(t, q.TryEnqueue(2))
+	assert.Equal(t, ErrOutOfCapacity, q.TryEnqueue(3))
+	peek, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 1, peek)
+	assert.Equal(t, 2, q.Len())
+
+	val, err := q.TryDequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	// 出队之后又可以入队了
+	require.NoError(t, q.TryEnqueue(3))
+	assert.Equal(t, []int{2, 3}, q.AsSlice())
+
+	q.Close()
+	assert.Equal(t, ErrQueueClosed, q.TryEnqueue(4))
+	val, err = q.TryDequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+	val, err = q.TryDequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	_, err = q.TryDequeue()
+	assert.Equal(t, ErrQueueClosed, err)
+}
+
+func TestConcurrentArrayBlockingQueue_EnqueueBatch(t *testing.T) {
+	testCases := []struct {
+		name      string
+		queue     func() *ConcurrentArrayBlockingQueue[int]
+		vals      []int
+		timeout   time.Duration
+		wantErr   error
+		wantSlice []int
+	}{
+		{
+			name: "empty batch",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				return NewConcurrentArrayBlockingQueue[int](3)
+			},
+			vals:      []int{},
+			timeout:   time.Second,
+			wantSlice: []int{},
+		},
+		{
+			name: "enqueued",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				require.NoError(t, q.TryEnqueue(1))
+				return q
+			},
+			vals:      []int{2, 3},
+			timeout:   time.Second,
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name: "more than capacity",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				return NewConcurrentArrayBlockingQueue[int](3)
+			},
+			vals:      []int{1, 2, 3, 4},
+			timeout:   time.Second,
+			wantErr:   ErrOutOfCapacity,
+			wantSlice: []int{},
+		},
+		{
+			// 空位不够，一个都不会入队
+			name: "not enough space and timeout",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				require.NoError(t, q.TryEnqueue(1))
+				require.NoError(t, q.TryEnqueue(2))
+				return q
+			},
+			vals:      []int{3, 4},
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{1, 2},
+		},
+		{
+			name: "closed",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				q.Close()
+				return q
+			},
+			vals:      []int{1},
+			timeout:   time.Second,
+			wantErr:   ErrQueueClosed,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.queue()
+			err := q.EnqueueBatch(ctx, tc.vals...)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+		})
+	}
+	// 空位不够的时候阻塞，出队之后批量入队成功
+	t.Run("blocking and dequeue", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.EnqueueBatch(ctx, 1, 2))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			val, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, val)
+		}()
+		require.NoError(t, q.EnqueueBatch(ctx, 3, 4))
+		assert.Equal(t, []int{2, 3, 4}, q.AsSlice())
+	})
+}
+
+func TestConcurrentArrayBlockingQueue_DrainTo(t *testing.T) {
+	testCases := []struct {
+		name      string
+		queue     func() *ConcurrentArrayBlockingQueue[int]
+		dst       []int
+		limit     int
+		timeout   time.Duration
+		wantErr   error
+		wantRes   []int
+		wantSlice []int
+	}{
+		{
+			name: "empty and timeout",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				return NewConcurrentArrayBlockingQueue[int](3)
+			},
+			limit:     2,
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+		{
+			name: "less than limit",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				require.NoError(t, q.EnqueueBatch(context.Background(), 1, 2))
+				return q
+			},
+			dst:       []int{0},
+			limit:     3,
+			timeout:   time.Second,
+			wantRes:   []int{0, 1, 2},
+			wantSlice: []int{},
+		},
+		{
+			name: "more than limit",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				require.NoError(t, q.EnqueueBatch(context.Background(), 1, 2, 3))
+				return q
+			},
+			limit:     2,
+			timeout:   time.Second,
+			wantRes:   []int{1, 2},
+			wantSlice: []int{3},
+		},
+		{
+			name: "no limit",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				require.NoError(t, q.EnqueueBatch(context.Background(), 1, 2, 3))
+				return q
+			},
+			limit:     0,
+			timeout:   time.Second,
+			wantRes:   []int{1, 2, 3},
+			wantSlice: []int{},
+		},
+		{
+			name: "closed with elements",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				require.NoError(t, q.EnqueueBatch(context.Background(), 1, 2, 3))
+				q.Close()
+				return q
+			},
+			limit:     2,
+			timeout:   time.Second,
+			wantRes:   []int{1, 2},
+			wantSlice: []int{3},
+		},
+		{
+			name: "closed and empty",
+			queue: func() *ConcurrentArrayBlockingQueue[int] {
+				q := NewConcurrentArrayBlockingQueue[int](3)
+				q.Close()
+				return q
+			},
+			limit:     2,
+			timeout:   time.Second,
+			wantErr:   ErrQueueClosed,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.queue()
+			res, err := q.DrainTo(ctx, tc.dst, tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+	// 出队之后，空出来的位置可以被入队使用
+	t.Run("release capacity", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.EnqueueBatch(ctx, 1, 2, 3))
+		res, err := q.DrainTo(ctx, nil, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, res)
+		require.NoError(t, q.EnqueueBatch(ctx, 4, 5, 6))
+		assert.Equal(t, []int{4, 5, 6}, q.AsSlice())
+	})
+	// 其他出队的 goroutine 已经拿到信号量但是还没拿到锁，给它们留下元素
+	t.Run("reserved", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](4)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.EnqueueBatch(ctx, 1, 2, 3, 4))
+		require.True(t, q.dequeueCap.TryAcquire(1))
+		q.reserved.Add(1)
+		res, err := q.DrainTo(ctx, nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, res)
+		assert.Equal(t, []int{4}, q.AsSlice())
+
+		// 拿到了信号量但是还没登记，只能取走自己那一个
+		require.NoError(t, q.EnqueueBatch(ctx, 5, 6))
+		require.True(t, q.dequeueCap.TryAcquire(1))
+		res, err = q.DrainTo(ctx, nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{4}, res)
+		assert.Equal(t, []int{5, 6}, q.AsSlice())
+	})
+	// 多个消费者并发 DrainTo，元素不多不少
+	t.Run("concurrent", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](8)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		var total atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					res, err := q.DrainTo(ctx, nil, 3)
+					if err == ErrQueueClosed {
+						return
+					}
+					assert.NoError(t, err)
+					total.Add(int64(len(res)))
+				}
+			}()
+		}
+		for i := 0; i < 200; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		q.Close()
+		wg.Wait()
+		assert.Equal(t, int64(200), total.Load())
+	})
+}
+
+func BenchmarkConcurrentArrayBlockingQueue_DrainTo(b *testing.B) {
+	ctx := context.Background()
+	batch := make([]int, 64)
+	b.Run("Dequeue", func(b *testing.B) {
+		q := NewConcurrentArrayBlockingQueue[int](64)
+		for i := 0; i < b.N; i++ {
+			_ = q.EnqueueBatch(ctx, batch...)
+			for j := 0; j < len(batch); j++ {
+				_, _ = q.Dequeue(ctx)
+			}
+		}
+	})
+	b.Run("DrainTo", func(b *testing.B) {
+		q := NewConcurrentArrayBlockingQueue[int](64)
+		dst := make([]int, 0, 64)
+		for i := 0; i < b.N; i++ {
+			_ = q.EnqueueBatch(ctx, batch...)
+			dst, _ = q.DrainTo(ctx, dst[:0], len(batch))
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+var _ BlockingQueue[any] = &ConcurrentBlockingDeque[any]{}
+
+// ConcurrentBlockingDeque 并发阻塞双端队列
+// 有界的时候，队列满了放入元素会阻塞；队列为空的时候取出元素会阻塞
+type ConcurrentBlockingDeque[T any] struct {
+	deque         *Deque[T]
+	mutex         *sync.RWMutex
+	capacity      int   // capacity <= 0 表示无界
+	enqueueSignal *cond // 有元素放入时广播，唤醒等待中的取出者
+	dequeueSignal *cond // 有元素取出时广播，唤醒等待中的放入者
+	zero          T
+}
+
+// NewConcurrentBlockingDeque 创建一个并发阻塞双端队列
+// capacity <= 0 表示无界队列，此时放入元素永远不会阻塞
+func NewConcurrentBlockingDeque[T any](capacity int) *ConcurrentBlockingDeque[T] {
+	m := &sync.RWMutex{}
+	return &ConcurrentBlockingDeque[T]{
+		deque:         NewDeque[T](capacity),
+		mutex:         m,
+		capacity:      capacity,
+		enqueueSignal: newCond(m),
+		dequeueSignal: newCond(m),
+	}
+}
+
+// Enqueue 在队尾放入元素，和 PushBack 相同
+func (c *ConcurrentBlockingDeque[T]) Enqueue(ctx context.Context, t T) error {
+	return c.PushBack(ctx, t)
+}
+
+// Dequeue 取出队头元素，和 PopFront 相同
+func (c *ConcurrentBlockingDeque[T]) Dequeue(ctx context.Context) (T, error) {
+	return c.PopFront(ctx)
+}
+
+// PushFront 在队头放入元素，队列满的时候会阻塞，直到有元素被取出或者 ctx 过期
+func (c *ConcurrentBlockingDeque[T]) PushFront(ctx context.Context, t T) error {
+	return c.push(ctx, t, c.deque.PushFront)
+}
+
+// PushBack 在队尾放入元素，队列满的时候会阻塞，直到有元素被取出或者 ctx 过期
+func (c *ConcurrentBlockingDeque[T]) PushBack(ctx context.Context, t T) error {
+	return c.push(ctx, t, c.deque.PushBack)
+}
+
+// PopFront 取出队头元素，队列为空的时候会阻塞，直到有元素放入或者 ctx 过期
+func (c *ConcurrentBlockingDeque[T]) PopFront(ctx context.Context) (T, error) {
+	return c.pop(ctx, c.deque.PopFront)
+}
+
+// PopBack 取出队尾元素，队列为空的时候会阻塞，直到有元素放入或者 ctx 过期
+func (c *ConcurrentBlockingDeque[T]) PopBack(ctx context.Context) (T, error) {
+	return c.pop(ctx, c.deque.PopBack)
+}
+
+// PeekFront 返回队头元素但不取出，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentBlockingDeque[T]) PeekFront() (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.PeekFront()
+}
+
+// PeekBack 返回队尾元素但不取出，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentBlockingDeque[T]) PeekBack() (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.PeekBack()
+}
+
+// Get 返回从队头开始第 index 个元素
+func (c *ConcurrentBlockingDeque[T]) Get(index int) (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.Get(index)
+}
+
+func (c *ConcurrentBlockingDeque[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.Len()
+}
+
+func (c *ConcurrentBlockingDeque[T]) AsSlice() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.AsSlice()
+}
+
+func (c *ConcurrentBlockingDeque[T]) push(ctx context.Context, t T, push func(t T)) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.mutex.Lock()
+		if c.capacity <= 0 || c.deque.Len() < c.capacity {
+			push(t)
+			c.enqueueSignal.broadcast()
+			return nil
+		}
+		// 队列已满，等待取出
+		signal := c.dequeueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+func (c *ConcurrentBlockingDeque[T]) pop(ctx context.Context, pop func() (T, error)) (T, error) {
+	for {
+		if ctx.Err() != nil {
+			return c.zero, ctx.Err()
+		}
+		c.mutex.Lock()
+		val, err := pop()
+		if err == nil {
+			c.dequeueSignal.broadcast()
+			return val, nil
+		}
+		// 队列为空，等待放入
+		signal := c.enqueueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return c.zero, ctx.Err()
+		case <-signal:
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentBlockingDeque_Push(t *testing.T) {
+	testCases := []struct {
+		name      string
+		capacity  int
+		data      []int
+		front     bool
+		val       int
+		timeout   time.Duration
+		wantErr   error
+		wantSlice []int
+	}{
+		{
+			name:      "push front",
+			capacity:  3,
+			data:      []int{1, 2},
+			front:     true,
+			val:       0,
+			timeout:   time.Second,
+			wantSlice: []int{0, 1, 2},
+		},
+		{
+			name:      "push back",
+			capacity:  3,
+			data:      []int{1, 2},
+			val:       3,
+			timeout:   time.Second,
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name:      "invalid context",
+			capacity:  3,
+			data:      []int{},
+			val:       3,
+			timeout:   -time.Second,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+		{
+			name:      "full and timeout",
+			capacity:  2,
+			data:      []int{1, 2},
+			front:     true,
+			val:       3,
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{1, 2},
+		},
+		{
+			name:      "boundless",
+			capacity:  0,
+			data:      []int{1, 2, 3, 4, 5, 6, 7, 8},
+			val:       9,
+			timeout:   time.Second,
+			wantSlice: []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentBlockingDeque[int](tc.capacity)
+			for _, v := range tc.data {
+				require.NoError(t, q.PushBack(context.Background(), v))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			var err error
+			if tc.front {
+				err = q.PushFront(ctx, tc.val)
+			} else {
+				err = q.PushBack(ctx, tc.val)
+			}
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), q.Len())
+		})
+	}
+	// 放入阻塞，而后取出，于是放入成功
+	t.Run("push blocking and pop", func(t *testing.T) {
+		q := NewConcurrentBlockingDeque[int](2)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.PushBack(ctx, 1))
+		require.NoError(t, q.PushBack(ctx, 2))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			val, err := q.PopBack(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, val)
+		}()
+		require.NoError(t, q.PushFront(ctx, 0))
+		assert.Equal(t, []int{0, 1}, q.AsSlice())
+	})
+}
+
+func TestConcurrentBlockingDeque_Pop(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      []int
+		front     bool
+		timeout   time.Duration
+		wantVal   int
+		wantErr   error
+		wantSlice []int
+	}{
+		{
+			name:      "pop front",
+			data:      []int{1, 2, 3},
+			front:     true,
+			timeout:   time.Second,
+			wantVal:   1,
+			wantSlice: []int{2, 3},
+		},
+		{
+			name:      "pop back",
+			data:      []int{1, 2, 3},
+			timeout:   time.Second,
+			wantVal:   3,
+			wantSlice: []int{1, 2},
+		},
+		{
+			name:      "empty and timeout",
+			data:      []int{},
+			front:     true,
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentBlockingDeque[int](3)
+			for _, v := range tc.data {
+				require.NoError(t, q.PushBack(context.Background(), v))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			var val int
+			var err error
+			if tc.front {
+				val, err = q.PopFront(ctx)
+			} else {
+				val, err = q.PopBack(ctx)
+			}
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+	// 取出阻塞，而后放入，于是取出成功
+	t.Run("pop blocking and push", func(t *testing.T) {
+		q := NewConcurrentBlockingDeque[int](0)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, q.PushFront(ctx, 123))
+		}()
+		val, err := q.PopBack(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, val)
+	})
+}
+
+func TestConcurrentBlockingDeque_Peek(t *testing.T) {
+	q := NewConcurrentBlockingDeque[int](3)
+	_, err := q.PeekFront()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = q.PeekBack()
+	assert.Equal(t, ErrEmptyQueue, err)
+	require.NoError(t, q.PushBack(context.Background(), 1))
+	require.NoError(t, q.PushBack(context.Background(), 2))
+	front, err := q.PeekFront()
+	require.NoError(t, err)
+	assert.Equal(t, 1, front)
+	back, err := q.PeekBack()
+	require.NoError(t, err)
+	assert.Equal(t, 2, back)
+	val, err := q.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+}
+
+func TestConcurrentBlockingDeque_Concurrent(t *testing.T) {
+	q := NewConcurrentBlockingDeque[int](4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(val int) {
+			defer wg.Done()
+			if val%2 == 0 {
+				assert.NoError(t, q.PushFront(ctx, val))
+			} else {
+				assert.NoError(t, q.PushBack(ctx, val))
+			}
+		}(i)
+		go func(val int) {
+			defer wg.Done()
+			var err error
+			if val%2 == 0 {
+				_, err = q.PopFront(ctx)
+			} else {
+				_, err = q.PopBack(ctx)
+			}
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 0, q.Len())
+}
@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"context"
+	"github.com/zmsocc/generic"
+	"github.com/zmsocc/generic/internal/queue"
+	"sync"
+)
+
+var _ BlockingQueue[any] = &ConcurrentBlockingPriorityQueue[any]{}
+
+// ConcurrentBlockingPriorityQueue 并发阻塞优先级队列
+// 有界的时候，队列满了入队会阻塞；队列为空的时候出队会阻塞
+type ConcurrentBlockingPriorityQueue[T any] struct {
+	q             *queue.PriorityQueue[T]
+	mutex         *sync.Mutex
+	enqueueSignal *cond // 有元素入队时广播，唤醒等待中的出队者
+	dequeueSignal *cond // 有元素出队时广播，唤醒等待中的入队者
+	zero          T
+}
+
+// NewConcurrentBlockingPriorityQueue 创建一个并发阻塞优先级队列
+// capacity <= 0 表示无界队列，此时入队永远不会阻塞
+func NewConcurrentBlockingPriorityQueue[T any](capacity int, compare generic.Comparator[T],
+	opts ...PriorityQueueOption[T]) *ConcurrentBlockingPriorityQueue[T] {
+	m := &sync.Mutex{}
+	return &ConcurrentBlockingPriorityQueue[T]{
+		q:             queue.NewPriorityQueue[T](capacity, compare, opts...),
+		mutex:         m,
+		enqueueSignal: newCond(m),
+		dequeueSignal: newCond(m),
+	}
+}
+
+// Enqueue 入队
+// 队列满的时候会阻塞，直到有元素出队或者 ctx 过期
+func (c *ConcurrentBlockingPriorityQueue[T]) Enqueue(ctx context.Context, t T) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.mutex.Lock()
+		err := c.q.Enqueue(t)
+		if err == nil {
+			c.enqueueSignal.broadcast()
+			return nil
+		}
+		// 队列已满，等待出队
+		signal := c.dequeueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// Dequeue 出队
+// 队列为空的时候会阻塞，直到有元素入队或者 ctx 过期
+func (c *ConcurrentBlockingPriorityQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		if ctx.Err() != nil {
+			return c.zero, ctx.Err()
+		}
+		c.mutex.Lock()
+		val, err := c.q.Dequeue()
+		if err == nil {
+			c.dequeueSignal.broadcast()
+			return val, nil
+		}
+		// 队列为空，等待入队
+		signal := c.enqueueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return c.zero, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// TryEnqueue 非阻塞入队，队列满的时候返回 ErrOutOfCapacity
+func (c *ConcurrentBlockingPriorityQueue[T]) TryEnqueue(t T) error {
+	c.mutex.Lock()
+	err := c.q.Enqueue(t)
+	if err != nil {
+		c.mutex.Unlock()
+		return err
+	}
+	c.enqueueSignal.broadcast()
+	return nil
+}
+
+// TryDequeue 非阻塞出队，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentBlockingPriorityQueue[T]) TryDequeue() (T, error) {
+	c.mutex.Lock()
+	val, err := c.q.Dequeue()
+	if err != nil {
+		c.mutex.Unlock()
+		return c.zero, err
+	}
+	c.dequeueSignal.broadcast()
+	return val, nil
+}
+
+// Peek 返回队头元素但不出队，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentBlockingPriorityQueue[T]) Peek() (T, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.Peek()
+}
+
+func (c *ConcurrentBlockingPriorityQueue[T]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.Len()
+}
+
+// Cap 返回容量，无界队列返回 0
+func (c *ConcurrentBlockingPriorityQueue[T]) Cap() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.Cap()
+}
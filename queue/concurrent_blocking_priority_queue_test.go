@@ -0,0 +1,190 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentBlockingPriorityQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		val      int
+		timeout  time.Duration
+		wantErr  error
+		wantLen  int
+		wantPeek int
+	}{
+		{
+			name:     "empty and enqueued",
+			capacity: 3,
+			data:     []int{},
+			val:      10,
+			timeout:  time.Second,
+			wantLen:  1,
+			wantPeek: 10,
+		},
+		{
+			name:     "invalid context",
+			capacity: 3,
+			data:     []int{},
+			val:      10,
+			timeout:  -time.Second,
+			wantErr:  context.DeadlineExceeded,
+		},
+		{
+			name:     "full and timeout",
+			capacity: 3,
+			data:     []int{3, 2, 1},
+			val:      0,
+			timeout:  time.Millisecond * 100,
+			wantErr:  context.DeadlineExceeded,
+			wantLen:  3,
+			wantPeek: 1,
+		},
+		{
+			name:     "boundless",
+			capacity: 0,
+			data:     []int{3, 2, 1},
+			val:      0,
+			timeout:  time.Second,
+			wantLen:  4,
+			wantPeek: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentBlockingPriorityQueue[int](tc.capacity, generic.ComparatorOrdered[int])
+			for _, v := range tc.data {
+				require.NoError(t, q.TryEnqueue(v))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			err := q.Enqueue(ctx, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			if q.Len() == 0 {
+				return
+			}
+			peek, err := q.Peek()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPeek, peek)
+		})
+	}
+	// 入队阻塞，而后出队，于是入队成功
+	t.Run("enqueue blocking and dequeue", func(t *testing.T) {
+		q := NewConcurrentBlockingPriorityQueue[int](2, generic.ComparatorOrdered[int])
+		require.NoError(t, q.TryEnqueue(2))
+		require.NoError(t, q.TryEnqueue(1))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			val, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, val)
+		}()
+		err := q.Enqueue(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 2, q.Len())
+	})
+}
+
+func TestConcurrentBlockingPriorityQueue_Dequeue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []int
+		timeout time.Duration
+		wantRes int
+		wantErr error
+	}{
+		{
+			name:    "empty and timeout",
+			data:    []int{},
+			timeout: time.Millisecond * 100,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "invalid context",
+			data:    []int{1},
+			timeout: -time.Second,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "dequeue min",
+			data:    []int{6, 5, 4, 3, 2, 1},
+			timeout: time.Second,
+			wantRes: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentBlockingPriorityQueue[int](10, generic.ComparatorOrdered[int])
+			for _, v := range tc.data {
+				require.NoError(t, q.TryEnqueue(v))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			res, err := q.Dequeue(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+	// 出队阻塞，而后入队，于是出队成功
+	t.Run("dequeue blocking and enqueue", func(t *testing.T) {
+		q := NewConcurrentBlockingPriorityQueue[int](2, generic.ComparatorOrdered[int])
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, q.Enqueue(ctx, 123))
+		}()
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, val)
+	})
+}
+
+func TestConcurrentBlockingPriorityQueue_Try(t *testing.T) {
+	q := NewConcurrentBlockingPriorityQueue[int](1, generic.ComparatorOrdered[int])
+	_, err := q.TryDequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = q.Peek()
+	assert.Equal(t, ErrEmptyQueue, err)
+	require.NoError(t, q.TryEnqueue(1))
+	assert.Equal(t, ErrOutOfCapacity, q.TryEnqueue(2))
+	val, err := q.TryDequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 1, q.Cap())
+}
+
+func TestConcurrentBlockingPriorityQueue_Concurrent(t *testing.T) {
+	q := NewConcurrentBlockingPriorityQueue[int](5, generic.ComparatorOrdered[int])
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(val int) {
+			defer wg.Done()
+			assert.NoError(t, q.Enqueue(ctx, val))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, q.Len())
+}
@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"context"
+	"github.com/zmsocc/generic/list"
+	"sync"
+)
+
+var _ BlockingQueue[any] = &ConcurrentLinkedBlockingQueue[any]{}
+
+// ConcurrentLinkedBlockingQueue 基于链表的并发阻塞队列
+// 有界的时候，队列满了入队会阻塞；队列为空的时候出队会阻塞
+type ConcurrentLinkedBlockingQueue[T any] struct {
+	linkedList    *list.LinkedList[T]
+	mutex         *sync.RWMutex
+	capacity      int   // capacity <= 0 表示无界
+	enqueueSignal *cond // 有元素入队时广播，唤醒等待中的出队者
+	dequeueSignal *cond // 有元素出队时广播，唤醒等待中的入队者
+	zero          T
+}
+
+// NewConcurrentLinkedBlockingQueue 创建一个基于链表的并发阻塞队列
+// capacity <= 0 表示无界队列，此时入队永远不会阻塞
+func NewConcurrentLinkedBlockingQueue[T any](capacity int) *ConcurrentLinkedBlockingQueue[T] {
+	m := &sync.RWMutex{}
+	return &ConcurrentLinkedBlockingQueue[T]{
+		linkedList:    list.NewLinkedList[T](),
+		mutex:         m,
+		capacity:      capacity,
+		enqueueSignal: newCond(m),
+		dequeueSignal: newCond(m),
+	}
+}
+
+// Enqueue 入队
+// 队列满的时候会阻塞，直到有元素出队或者 ctx 过期
+func (c *ConcurrentLinkedBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.mutex.Lock()
+		if !c.isFull() {
+			err := c.linkedList.Append(t)
+			if err != nil {
+				c.mutex.Unlock()
+				return err
+			}
+			c.enqueueSignal.broadcast()
+			return nil
+		}
+		// 队列已满，等待出队
+		signal := c.dequeueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// Dequeue 出队
+// 队列为空的时候会阻塞，直到有元素入队或者 ctx 过期
+func (c *ConcurrentLinkedBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		if ctx.Err() != nil {
+			return c.zero, ctx.Err()
+		}
+		c.mutex.Lock()
+		if c.linkedList.Len() > 0 {
+			val, err := c.linkedList.Delete(0)
+			if err != nil {
+				c.mutex.Unlock()
+				return c.zero, err
+			}
+			c.dequeueSignal.broadcast()
+			return val, nil
+		}
+		// 队列为空，等待入队
+		signal := c.enqueueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return c.zero, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+func (c *ConcurrentLinkedBlockingQueue[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.linkedList.Len()
+}
+
+func (c *ConcurrentLinkedBlockingQueue[T]) AsSlice() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	res := c.linkedList.AsSlice()
+	if res == nil {
+		return []T{}
+	}
+	return res
+}
+
+func (c *ConcurrentLinkedBlockingQueue[T]) isFull() bool {
+	return c.capacity > 0 && c.linkedList.Len() >= c.capacity
+}
@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentLinkedBlockingQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name      string
+		queue     func() *ConcurrentLinkedBlockingQueue[int]
+		val       int
+		timeout   time.Duration
+		wantErr   error
+		wantSlice []int
+		wantLen   int
+	}{
+		{
+			name: "empty and enqueued",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				return NewConcurrentLinkedBlockingQueue[int](3)
+			},
+			val:       123,
+			timeout:   time.Second,
+			wantSlice: []int{123},
+			wantLen:   1,
+		},
+		{
+			name: "invalid context",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				return NewConcurrentLinkedBlockingQueue[int](3)
+			},
+			val:       123,
+			timeout:   -time.Second,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+		{
+			name: "enqueued full",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q := NewConcurrentLinkedBlockingQueue[int](3)
+				require.NoError(t, q.Enqueue(ctx, 123))
+				require.NoError(t, q.Enqueue(ctx, 234))
+				return q
+			},
+			val:       345,
+			timeout:   time.Second,
+			wantSlice: []int{123, 234, 345},
+			wantLen:   3,
+		},
+		{
+			name: "full and timeout",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q := NewConcurrentLinkedBlockingQueue[int](2)
+				require.NoError(t, q.Enqueue(ctx, 123))
+				require.NoError(t, q.Enqueue(ctx, 234))
+				return q
+			},
+			val:       345,
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{123, 234},
+			wantLen:   2,
+		},
+		{
+			// 无界队列永远不会满
+			name: "boundless",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q := NewConcurrentLinkedBlockingQueue[int](0)
+				for i := 0; i < 100; i++ {
+					require.NoError(t, q.Enqueue(ctx, i))
+				}
+				return q
+			},
+			val:     100,
+			timeout: time.Second,
+			wantSlice: func() []int {
+				res := make([]int, 101)
+				for i := range res {
+					res[i] = i
+				}
+				return res
+			}(),
+			wantLen: 101,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.queue()
+			err := q.Enqueue(ctx, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+			assert.Equal(t, tc.wantLen, q.Len())
+		})
+	}
+	// 入队阻塞，而后出队，于是入队成功
+	t.Run("enqueue blocking and dequeue", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](2)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, 123))
+		require.NoError(t, q.Enqueue(ctx, 234))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			val, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 123, val)
+		}()
+		require.NoError(t, q.Enqueue(ctx, 345))
+		assert.Equal(t, []int{234, 345}, q.AsSlice())
+	})
+}
+
+func TestConcurrentLinkedBlockingQueue_Dequeue(t *testing.T) {
+	testCases := []struct {
+		name      string
+		queue     func() *ConcurrentLinkedBlockingQueue[int]
+		timeout   time.Duration
+		wantErr   error
+		wantVal   int
+		wantSlice []int
+	}{
+		{
+			name: "dequeued",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q := NewConcurrentLinkedBlockingQueue[int](3)
+				require.NoError(t, q.Enqueue(ctx, 123))
+				require.NoError(t, q.Enqueue(ctx, 234))
+				return q
+			},
+			timeout:   time.Second,
+			wantVal:   123,
+			wantSlice: []int{234},
+		},
+		{
+			name: "dequeued last",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q := NewConcurrentLinkedBlockingQueue[int](0)
+				require.NoError(t, q.Enqueue(ctx, 123))
+				return q
+			},
+			timeout:   time.Second,
+			wantVal:   123,
+			wantSlice: []int{},
+		},
+		{
+			name: "invalid context",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q := NewConcurrentLinkedBlockingQueue[int](3)
+				require.NoError(t, q.Enqueue(ctx, 123))
+				return q
+			},
+			timeout:   -time.Second,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{123},
+		},
+		{
+			name: "empty and timeout",
+			queue: func() *ConcurrentLinkedBlockingQueue[int] {
+				return NewConcurrentLinkedBlockingQueue[int](3)
+			},
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.queue()
+			val, err := q.Dequeue(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+	// 出队阻塞，而后入队，于是出队成功
+	t.Run("dequeue blocking and enqueue", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](0)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, q.Enqueue(ctx, 123))
+		}()
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, val)
+	})
+}
+
+func TestConcurrentLinkedBlockingQueue_Concurrent(t *testing.T) {
+	q := NewConcurrentLinkedBlockingQueue[int](5)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	var wg sync.WaitGroup
+	res := make(chan int, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(val int) {
+			defer wg.Done()
+			assert.NoError(t, q.Enqueue(ctx, val))
+		}(i)
+		go func() {
+			defer wg.Done()
+			val, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+			res <- val
+		}()
+	}
+	wg.Wait()
+	close(res)
+	sum := 0
+	for val := range res {
+		sum += val
+	}
+	assert.Equal(t, 99*100/2, sum)
+	assert.Equal(t, 0, q.Len())
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+var _ Queue[any] = &ConcurrentLinkedQueue[any]{}
+
+// ConcurrentLinkedQueue 无界并发队列，基于 Michael-Scott 算法实现，不使用锁
+// 入队和出队都不会阻塞，队列为空的时候出队返回 ErrEmptyQueue
+type ConcurrentLinkedQueue[T any] struct {
+	// head 指向哨兵结点，真正的队头元素是 head.next
+	head   atomic.Pointer[linkedNode[T]]
+	tail   atomic.Pointer[linkedNode[T]]
+	length atomic.Int64
+}
+
+type linkedNode[T any] struct {
+	val  T
+	next atomic.Pointer[linkedNode[T]]
+}
+
+// NewConcurrentLinkedQueue 创建一个无锁并发队列
+func NewConcurrentLinkedQueue[T any]() *ConcurrentLinkedQueue[T] {
+	q := &ConcurrentLinkedQueue[T]{}
+	dummy := &linkedNode[T]{}
+	q.head.Store(dummy)
+	q.tail.Store(dummy)
+	return q
+}
+
+// Enqueue 入队，永远不会返回 error，返回值只是为了和其他队列保持一致
+func (c *ConcurrentLinkedQueue[T]) Enqueue(t T) error {
+	newNode := &linkedNode[T]{val: t}
+	for {
+		tail := c.tail.Load()
+		next := tail.next.Load()
+		if tail != c.tail.Load() { // tail 已经被别的 goroutine 修改了，重来
+			continue
+		}
+		if next != nil {
+			// tail 落后了，帮忙把 tail 往后推进一步再重试
+			c.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, newNode) {
+			// 推进 tail 失败也没关系，说明别的 goroutine 已经帮忙推进了
+			c.tail.CompareAndSwap(tail, newNode)
+			c.length.Add(1)
+			return nil
+		}
+	}
+}
+
+// Dequeue 出队，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentLinkedQueue[T]) Dequeue() (T, error) {
+	for {
+		head := c.head.Load()
+		tail := c.tail.Load()
+		next := head.next.Load()
+		if head != c.head.Load() { // head 已经被别的 goroutine 修改了，重来
+			continue
+		}
+		if head == tail {
+			if next == nil {
+				var zero T
+				return zero, ErrEmptyQueue
+			}
+			// 有元素入队了，但是 tail 还没来得及推进，帮忙推进之后再重试
+			c.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		// 必须在 CAS 之前读取，CAS 成功之后 next 就变成了新的哨兵结点
+		// 这里不能把 next.val 置为零值，因为别的 goroutine 可能正在读取它
+		val := next.val
+		if c.head.CompareAndSwap(head, next) {
+			c.length.Add(-1)
+			return val, nil
+		}
+	}
+}
+
+// Len 返回队列长度
+// 在并发入队出队的时候，这只是一个近似值
+func (c *ConcurrentLinkedQueue[T]) Len() int {
+	// 出队计数可能先于入队计数生效，短暂出现负数
+	return int(max(c.length.Load(), 0))
+}
@@ -0,0 +1,179 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentLinkedQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []int
+		val     int
+		wantLen int
+	}{
+		{
+			name:    "empty",
+			data:    []int{},
+			val:     123,
+			wantLen: 1,
+		},
+		{
+			name:    "non-empty",
+			data:    []int{1, 2, 3},
+			val:     123,
+			wantLen: 4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentLinkedQueue[int]()
+			for _, v := range tc.data {
+				require.NoError(t, q.Enqueue(v))
+			}
+			err := q.Enqueue(tc.val)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			res := drainLinkedQueue(q)
+			assert.Equal(t, append(tc.data, tc.val), res)
+		})
+	}
+}
+
+func TestConcurrentLinkedQueue_Dequeue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []int
+		wantVal int
+		wantErr error
+		wantLen int
+	}{
+		{
+			name:    "empty",
+			data:    []int{},
+			wantErr: ErrEmptyQueue,
+		},
+		{
+			name:    "one element",
+			data:    []int{1},
+			wantVal: 1,
+			wantLen: 0,
+		},
+		{
+			name:    "many elements",
+			data:    []int{1, 2, 3},
+			wantVal: 1,
+			wantLen: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentLinkedQueue[int]()
+			for _, v := range tc.data {
+				require.NoError(t, q.Enqueue(v))
+			}
+			val, err := q.Dequeue()
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+}
+
+// 多个生产者和消费者并发，所有元素都只被消费一次，并且同一个生产者的元素保持先进先出
+func TestConcurrentLinkedQueue_Concurrent(t *testing.T) {
+	const producers, perProducer = 8, 1000
+	q := NewConcurrentLinkedQueue[int]()
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				assert.NoError(t, q.Enqueue(p*perProducer+i))
+			}
+		}(p)
+	}
+	var mutex sync.Mutex
+	res := make([]int, 0, producers*perProducer)
+	for c := 0; c < 4; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := make(map[int]int, producers)
+			for i := 0; i < producers*perProducer/4; {
+				val, err := q.Dequeue()
+				if err == ErrEmptyQueue {
+					runtime.Gosched()
+					continue
+				}
+				i++
+				p := val / perProducer
+				if prev, ok := last[p]; ok {
+					assert.Less(t, prev, val)
+				}
+				last[p] = val
+				mutex.Lock()
+				res = append(res, val)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Ints(res)
+	for i, v := range res {
+		require.Equal(t, i, v)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func BenchmarkConcurrentLinkedQueue(b *testing.B) {
+	b.Run("ConcurrentLinkedQueue", func(b *testing.B) {
+		q := NewConcurrentLinkedQueue[int]()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = q.Enqueue(1)
+				_, _ = q.Dequeue()
+			}
+		})
+	})
+	b.Run("ConcurrentArrayBlockingQueue", func(b *testing.B) {
+		q := NewConcurrentArrayBlockingQueue[int](1024)
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = q.Enqueue(ctx, 1)
+				_, _ = q.Dequeue(ctx)
+			}
+		})
+	})
+	b.Run("ConcurrentLinkedBlockingQueue", func(b *testing.B) {
+		q := NewConcurrentLinkedBlockingQueue[int](0)
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = q.Enqueue(ctx, 1)
+				_, _ = q.Dequeue(ctx)
+			}
+		})
+	})
+}
+
+func drainLinkedQueue(q *ConcurrentLinkedQueue[int]) []int {
+	res := make([]int, 0, q.Len())
+	for {
+		val, err := q.Dequeue()
+		if err != nil {
+			return res
+		}
+		res = append(res, val)
+	}
+}
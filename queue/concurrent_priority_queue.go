@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"github.com/zmsocc/generic"
+	"github.com/zmsocc/generic/internal/queue"
+	"sync"
+)
+
+var _ Queue[any] = &ConcurrentPriorityQueue[any]{}
+
+// ConcurrentPriorityQueue 并发安全的优先级队列，不会阻塞
+// 队列满的时候入队返回 ErrOutOfCapacity，队列为空的时候出队返回 ErrEmptyQueue
+type ConcurrentPriorityQueue[T any] struct {
+	q     *queue.PriorityQueue[T]
+	mutex *sync.RWMutex
+}
+
+// NewConcurrentPriorityQueue 创建一个并发安全的优先级队列
+// capacity <= 0 表示无界队列
+func NewConcurrentPriorityQueue[T any](capacity int, compare generic.Comparator[T], opts ...PriorityQueueOption[T]) *ConcurrentPriorityQueue[T] {
+	return &ConcurrentPriorityQueue[T]{
+		q:     queue.NewPriorityQueue[T](capacity, compare, opts...),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// NewConcurrentPriorityQueueOf 用 src 构造并发安全的优先级队列，时间复杂度为 O(n)
+// 有界的时候 src 的长度不能超过 capacity，否则返回 ErrOutOfCapacity
+func NewConcurrentPriorityQueueOf[T any](src []T, capacity int, compare generic.Comparator[T],
+	opts ...PriorityQueueOption[T]) (*ConcurrentPriorityQueue[T], error) {
+	q, err := queue.NewPriorityQueueOf[T](src, capacity, compare, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ConcurrentPriorityQueue[T]{
+		q:     q,
+		mutex: &sync.RWMutex{},
+	}, nil
+}
+
+func (c *ConcurrentPriorityQueue[T]) Enqueue(t T) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.Enqueue(t)
+}
+
+func (c *ConcurrentPriorityQueue[T]) Dequeue() (T, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.Dequeue()
+}
+
+// EnqueueAll 批量入队，放不下所有元素的时候返回 ErrOutOfCapacity，并且一个元素都不会入队
+func (c *ConcurrentPriorityQueue[T]) EnqueueAll(src ...T) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.EnqueueAll(src...)
+}
+
+// DequeueN 按照优先级顺序出队最多 n 个元素，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentPriorityQueue[T]) DequeueN(n int) ([]T, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.DequeueN(n)
+}
+
+// Drain 按照优先级顺序出队所有元素
+func (c *ConcurrentPriorityQueue[T]) Drain() []T {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.q.Drain()
+}
+
+func (c *ConcurrentPriorityQueue[T]) Peek() (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.q.Peek()
+}
+
+func (c *ConcurrentPriorityQueue[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.q.Len()
+}
+
+// Cap 返回容量，无界队列返回 0
+func (c *ConcurrentPriorityQueue[T]) Cap() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.q.Cap()
+}
+
+// AsSlice 返回元素的副本，顺序为堆内部的存储顺序
+func (c *ConcurrentPriorityQueue[T]) AsSlice() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.q.AsSlice()
+}
+
+// SortedSlice 返回按照优先级排序的元素副本
+func (c *ConcurrentPriorityQueue[T]) SortedSlice() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.q.SortedSlice()
+}
@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentPriorityQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		val      int
+		wantErr  error
+		wantLen  int
+	}{
+		{
+			name:     "有界空队列",
+			capacity: 3,
+			data:     []int{},
+			val:      10,
+			wantLen:  1,
+		},
+		{
+			name:     "有界满队列",
+			capacity: 3,
+			data:     []int{1, 2, 3},
+			val:      10,
+			wantErr:  ErrOutOfCapacity,
+			wantLen:  3,
+		},
+		{
+			name:     "无界非空队列",
+			capacity: 0,
+			data:     []int{6, 5, 4, 3, 2, 1},
+			val:      10,
+			wantLen:  7,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentPriorityQueue[int](tc.capacity, generic.ComparatorOrdered[int])
+			for _, v := range tc.data {
+				require.NoError(t, q.Enqueue(v))
+			}
+			err := q.Enqueue(tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			assert.Equal(t, tc.capacity, q.Cap())
+		})
+	}
+}
+
+func TestConcurrentPriorityQueue_Dequeue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []int
+		wantErr  error
+		wantRes  int
+		wantPeek int
+	}{
+		{
+			name:    "empty queue",
+			data:    []int{},
+			wantErr: ErrEmptyQueue,
+		},
+		{
+			name:     "non-empty queue",
+			data:     []int{6, 5, 4, 3, 2, 1},
+			wantRes:  1,
+			wantPeek: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentPriorityQueue[int](0, generic.ComparatorOrdered[int])
+			for _, v := range tc.data {
+				require.NoError(t, q.Enqueue(v))
+			}
+			res, err := q.Dequeue()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRes, res)
+			peek, err := q.Peek()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPeek, peek)
+		})
+	}
+}
+
+func TestConcurrentPriorityQueue_Bulk(t *testing.T) {
+	_, err := NewConcurrentPriorityQueueOf([]int{3, 2, 1}, 2, generic.ComparatorOrdered[int])
+	assert.Equal(t, ErrOutOfCapacity, err)
+
+	q, err := NewConcurrentPriorityQueueOf([]int{6, 4, 2}, 6, generic.ComparatorOrdered[int])
+	require.NoError(t, err)
+	assert.Equal(t, ErrOutOfCapacity, q.EnqueueAll(1, 3, 5, 7))
+	require.NoError(t, q.EnqueueAll(1, 3, 5))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, q.SortedSlice())
+	assert.Equal(t, 6, len(q.AsSlice()))
+
+	res, err := q.DequeueN(2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, res)
+	assert.Equal(t, []int{3, 4, 5, 6}, q.Drain())
+	_, err = q.DequeueN(2)
+	assert.Equal(t, ErrEmptyQueue, err)
+}
+
+func TestConcurrentPriorityQueue_Concurrent(t *testing.T) {
+	q := NewConcurrentPriorityQueue[int](0, generic.ComparatorOrdered[int])
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(val int) {
+			defer wg.Done()
+			assert.NoError(t, q.Enqueue(val))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 100, q.Len())
+	for i := 0; i < 100; i++ {
+		val, err := q.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, i, val)
+	}
+}
@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"sync"
+)
+
+// ConcurrentRingBuffer 并发安全的环形缓冲区
+type ConcurrentRingBuffer[T any] struct {
+	rb    *RingBuffer[T]
+	mutex *sync.RWMutex
+}
+
+// NewConcurrentRingBuffer 创建一个并发安全的环形缓冲区
+// capacity 必须为正数
+func NewConcurrentRingBuffer[T any](capacity int) *ConcurrentRingBuffer[T] {
+	return &ConcurrentRingBuffer[T]{
+		rb:    NewRingBuffer[T](capacity),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+func (c *ConcurrentRingBuffer[T]) Write(t T) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.rb.Write(t)
+}
+
+func (c *ConcurrentRingBuffer[T]) Snapshot() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.rb.Snapshot()
+}
+
+func (c *ConcurrentRingBuffer[T]) Last(n int) []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.rb.Last(n)
+}
+
+func (c *ConcurrentRingBuffer[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.rb.Len()
+}
+
+func (c *ConcurrentRingBuffer[T]) Cap() int {
+	return c.rb.Cap()
+}
+
+// NewReader 创建一个读游标，从当前最旧的元素开始读
+func (c *ConcurrentRingBuffer[T]) NewReader() *RingBufferReader[T] {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	reader := c.rb.NewReader()
+	reader.mutex = c.mutex
+	return reader
+}
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentRingBuffer(t *testing.T) {
+	r := NewConcurrentRingBuffer[int](16)
+	reader := r.NewReader()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(base int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Write(base*100 + j)
+			}
+		}(i)
+	}
+	// 写入的同时读取，读到的加上错过的不会超过写入的总数
+	total := 0
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_, missed, err := reader.Read()
+			if err == nil {
+				total++
+			}
+			total += missed
+			_ = r.Snapshot()
+			_ = r.Last(3)
+		}
+	}()
+	wg.Wait()
+	assert.LessOrEqual(t, total, 800)
+	assert.Equal(t, 16, r.Len())
+	assert.Equal(t, 16, r.Cap())
+	assert.Equal(t, 16, len(r.Snapshot()))
+
+	// 读完剩下的元素，读到的加上错过的刚好等于写入的总数
+	for {
+		_, missed, err := reader.Read()
+		total += missed
+		if err == ErrEmptyQueue {
+			break
+		}
+		total++
+	}
+	assert.Equal(t, 800, total)
+}
@@ -0,0 +1,36 @@
+package queue
+
+import "sync"
+
+// cond 基于 channel 实现的条件变量
+// 和 sync.Cond 不同的是，等待方拿到的是一个 channel，因此可以和 ctx.Done() 一起 select，
+// 从而支持超时控制
+type cond struct {
+	signal chan struct{}
+	l      sync.Locker
+}
+
+func newCond(l sync.Locker) *cond {
+	return &cond{
+		signal: make(chan struct{}),
+		l:      l,
+	}
+}
+
+// broadcast 唤醒所有等待者
+// 调用前必须持有锁，调用之后锁会被释放
+func (c *cond) broadcast() {
+	signal := make(chan struct{})
+	old := c.signal
+	c.signal = signal
+	c.l.Unlock()
+	close(old)
+}
+
+// signalCh 返回一个 channel，在下一次 broadcast 的时候会被关闭
+// 调用前必须持有锁，调用之后锁会被释放
+func (c *cond) signalCh() <-chan struct{} {
+	res := c.signal
+	c.l.Unlock()
+	return res
+}
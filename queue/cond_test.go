@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCond(t *testing.T) {
+	m := &sync.Mutex{}
+	c := newCond(m)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		m.Lock()
+		signal := c.signalCh()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-signal:
+			case <-time.After(time.Second):
+				assert.Fail(t, "没有被唤醒")
+			}
+		}()
+	}
+	m.Lock()
+	c.broadcast()
+	wg.Wait()
+	// broadcast 之后锁已经释放
+	assert.True(t, m.TryLock())
+	m.Unlock()
+}
@@ -0,0 +1,226 @@
+package queue
+
+import (
+	"context"
+	"github.com/zmsocc/generic/set"
+	"sync"
+	"time"
+)
+
+var _ BlockingQueue[any] = &DedupQueue[any, int]{}
+
+const (
+	defaultBackoffBase = 5 * time.Millisecond
+	defaultBackoffMax  = 1000 * time.Second
+)
+
+// DedupQueue 去重的并发阻塞队列，同一个 key 最多只有一个元素在排队，类似于 Kubernetes 的 workqueue
+//   - 入队的时候，如果 key 相同的元素已经在排队，新元素会被丢弃，或者通过 merge 和排队中的元素合并
+//   - 出队之后元素进入处理中的状态，处理完之后必须调用 Done。处理过程中再次入队的元素不会被立刻出队，
+//     而是等到 Done 之后才重新排队，保证同一个 key 不会被多个消费者同时处理
+//
+// 队列没有容量限制，队列为空的时候出队会阻塞
+type DedupQueue[T any, K comparable] struct {
+	key   func(t T) K
+	merge func(old, t T) T
+
+	mutex         *sync.Mutex
+	enqueueSignal *cond // 有元素可以出队时广播，唤醒等待中的出队者
+	queue         *Deque[K]
+	values        map[K]T
+	dirty         *set.MapSet[K] // 等待处理的 key，包括排队中的和处理中又被入队的
+	processing    *set.MapSet[K] // 处理中的 key
+	closed        bool
+
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	failures    map[K]int
+	timers      *set.MapSet[*time.Timer]
+	zero        T
+}
+
+// NewDedupQueue 创建一个去重队列，key 用于计算元素的 key
+// merge 为 nil 的时候，key 已经在排队的元素会被丢弃；否则使用 merge 的返回值替换排队中的元素
+func NewDedupQueue[T any, K comparable](key func(t T) K, merge func(old, t T) T) *DedupQueue[T, K] {
+	return NewDedupQueueWithBackoff[T, K](key, merge, defaultBackoffBase, defaultBackoffMax)
+}
+
+// NewDedupQueueWithBackoff 创建一个去重队列，并且指定 AddRateLimited 使用的指数退避参数
+// 第 n 次调用 AddRateLimited 的延迟为 base * 2^(n-1)，但是不超过 maxDelay
+func NewDedupQueueWithBackoff[T any, K comparable](key func(t T) K, merge func(old, t T) T,
+	base, maxDelay time.Duration) *DedupQueue[T, K] {
+	m := &sync.Mutex{}
+	return &DedupQueue[T, K]{
+		key:           key,
+		merge:         merge,
+		mutex:         m,
+		enqueueSignal: newCond(m),
+		queue:         NewDeque[K](0),
+		values:        make(map[K]T),
+		dirty:         set.NewMapSet[K](8),
+		processing:    set.NewMapSet[K](8),
+		backoffBase:   base,
+		backoffMax:    maxDelay,
+		failures:      make(map[K]int),
+		timers:        set.NewMapSet[*time.Timer](8),
+	}
+}
+
+// Enqueue 入队，队列没有容量限制，因此不会阻塞，ctx 已经过期的时候返回 ctx.Err()
+// key 相同的元素已经在排队的时候，不会新增排队的元素。队列关闭之后返回 ErrQueueClosed
+func (d *DedupQueue[T, K]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	k := d.key(t)
+	if d.dirty.Exist(k) {
+		if d.merge != nil {
+			d.values[k] = d.merge(d.values[k], t)
+		}
+		d.mutex.Unlock()
+		return nil
+	}
+	d.dirty.Add(k)
+	d.values[k] = t
+	if d.processing.Exist(k) {
+		// 正在处理，等到 Done 之后再排队
+		d.mutex.Unlock()
+		return nil
+	}
+	d.queue.PushBack(k)
+	d.enqueueSignal.broadcast()
+	return nil
+}
+
+// Dequeue 出队，出队的元素进入处理中的状态，处理完之后必须调用 Done
+// 队列为空的时候会阻塞，直到有元素入队或者 ctx 过期
+// 队列关闭之后，排队中的元素依旧可以出队，全部出队之后返回 ErrQueueClosed
+func (d *DedupQueue[T, K]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		if ctx.Err() != nil {
+			return d.zero, ctx.Err()
+		}
+		d.mutex.Lock()
+		if k, err := d.queue.PopFront(); err == nil {
+			val := d.values[k]
+			delete(d.values, k)
+			d.dirty.Delete(k)
+			d.processing.Add(k)
+			d.mutex.Unlock()
+			return val, nil
+		}
+		if d.closed {
+			d.mutex.Unlock()
+			return d.zero, ErrQueueClosed
+		}
+		// 队列为空，等待入队
+		signal := d.enqueueSignal.signalCh()
+		select {
+		case <-ctx.Done():
+			return d.zero, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// Done 标记元素处理完成，如果处理过程中 key 相同的元素又入队了，它会重新排队
+// 没有出队过的元素调用 Done 不会有任何效果
+func (d *DedupQueue[T, K]) Done(t T) {
+	d.mutex.Lock()
+	k := d.key(t)
+	if !d.processing.Exist(k) {
+		d.mutex.Unlock()
+		return
+	}
+	d.processing.Delete(k)
+	if !d.dirty.Exist(k) {
+		d.mutex.Unlock()
+		return
+	}
+	d.queue.PushBack(k)
+	d.enqueueSignal.broadcast()
+}
+
+// AddAfter 在 delay 之后入队，delay <= 0 的时候立刻入队
+// 队列关闭之后，还没有到期的元素会被丢弃
+func (d *DedupQueue[T, K]) AddAfter(t T, delay time.Duration) {
+	if delay <= 0 {
+		_ = d.Enqueue(context.Background(), t)
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.closed {
+		return
+	}
+	var timer *time.Timer
+	// 在锁里面创建 timer，回调拿到锁的时候 timer 一定已经赋值了
+	timer = time.AfterFunc(delay, func() {
+		d.mutex.Lock()
+		d.timers.Delete(timer)
+		d.mutex.Unlock()
+		_ = d.Enqueue(context.Background(), t)
+	})
+	d.timers.Add(timer)
+}
+
+// AddRateLimited 按照指数退避的延迟入队，同一个 key 每调用一次，延迟翻倍
+// 通常在处理失败之后调用，处理成功之后应当调用 Forget 重置延迟
+func (d *DedupQueue[T, K]) AddRateLimited(t T) {
+	d.mutex.Lock()
+	k := d.key(t)
+	n := d.failures[k]
+	d.failures[k] = n + 1
+	d.mutex.Unlock()
+	d.AddAfter(t, d.backoff(n))
+}
+
+// Forget 重置 AddRateLimited 的退避延迟
+func (d *DedupQueue[T, K]) Forget(t T) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.failures, d.key(t))
+}
+
+// NumRequeues 返回元素通过 AddRateLimited 重新入队的次数
+func (d *DedupQueue[T, K]) NumRequeues(t T) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.failures[d.key(t)]
+}
+
+// Len 返回排队中的元素个数，不包括处理中的元素
+func (d *DedupQueue[T, K]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.queue.Len()
+}
+
+// Close 关闭队列，之后不能再入队，阻塞中的出队者会在排队的元素全部出队之后返回 ErrQueueClosed
+func (d *DedupQueue[T, K]) Close() {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return
+	}
+	d.closed = true
+	for _, timer := range d.timers.Keys() {
+		timer.Stop()
+		d.timers.Delete(timer)
+	}
+	d.enqueueSignal.broadcast()
+}
+
+// backoff 第 n + 1 次重试的延迟
+func (d *DedupQueue[T, K]) backoff(n int) time.Duration {
+	delay := d.backoffBase
+	for i := 0; i < n && delay < d.backoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, d.backoffMax)
+}
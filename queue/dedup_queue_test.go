@@ -0,0 +1,206 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type dedupTask struct {
+	key   string
+	count int
+}
+
+func dedupTaskKey(t dedupTask) string {
+	return t.key
+}
+
+func TestDedupQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		merge   func(old, t dedupTask) dedupTask
+		tasks   []dedupTask
+		wantRes []dedupTask
+	}{
+		{
+			name: "ignore duplicate",
+			tasks: []dedupTask{
+				{key: "a", count: 1},
+				{key: "b", count: 1},
+				{key: "a", count: 2},
+			},
+			wantRes: []dedupTask{
+				{key: "a", count: 1},
+				{key: "b", count: 1},
+			},
+		},
+		{
+			name: "merge duplicate",
+			merge: func(old, t dedupTask) dedupTask {
+				old.count += t.count
+				return old
+			},
+			tasks: []dedupTask{
+				{key: "a", count: 1},
+				{key: "b", count: 1},
+				{key: "a", count: 2},
+				{key: "a", count: 3},
+			},
+			wantRes: []dedupTask{
+				{key: "a", count: 6},
+				{key: "b", count: 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewDedupQueue[dedupTask, string](dedupTaskKey, tc.merge)
+			for _, task := range tc.tasks {
+				require.NoError(t, q.Enqueue(context.Background(), task))
+			}
+			assert.Equal(t, len(tc.wantRes), q.Len())
+			for _, want := range tc.wantRes {
+				val, err := q.Dequeue(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, want, val)
+				q.Done(val)
+			}
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+// 处理过程中再次入队的元素，等到 Done 之后才会重新排队
+func TestDedupQueue_Processing(t *testing.T) {
+	q := NewDedupQueue[dedupTask, string](dedupTaskKey, nil)
+	require.NoError(t, q.Enqueue(context.Background(), dedupTask{key: "a", count: 1}))
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, val.count)
+
+	require.NoError(t, q.Enqueue(context.Background(), dedupTask{key: "a", count: 2}))
+	require.NoError(t, q.Enqueue(context.Background(), dedupTask{key: "a", count: 3}))
+	assert.Equal(t, 0, q.Len())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	q.Done(val)
+	assert.Equal(t, 1, q.Len())
+	val, err = q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, val.count)
+	q.Done(val)
+	assert.Equal(t, 0, q.Len())
+}
+
+// 没有出队过的元素调用 Done，不会重复排队
+func TestDedupQueue_DoneWithoutDequeue(t *testing.T) {
+	q := NewDedupQueue[dedupTask, string](dedupTaskKey, nil)
+	require.NoError(t, q.Enqueue(context.Background(), dedupTask{key: "a", count: 1}))
+	q.Done(dedupTask{key: "a"})
+	q.Done(dedupTask{key: "b"})
+	assert.Equal(t, 1, q.Len())
+
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, val.count)
+	assert.Equal(t, 0, q.Len())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// 多个消费者并发处理，同一个 key 不会被同时处理
+func TestDedupQueue_Concurrent(t *testing.T) {
+	q := NewDedupQueue[dedupTask, string](dedupTaskKey, nil)
+	keys := []string{"a", "b", "c", "d"}
+	var inFlight sync.Map
+	var processed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				val, err := q.Dequeue(context.Background())
+				if err != nil {
+					assert.Equal(t, ErrQueueClosed, err)
+					return
+				}
+				_, loaded := inFlight.LoadOrStore(val.key, struct{}{})
+				assert.False(t, loaded, "key %s 被同时处理", val.key)
+				time.Sleep(time.Microsecond * 10)
+				inFlight.Delete(val.key)
+				processed.Add(1)
+				q.Done(val)
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, q.Enqueue(context.Background(), dedupTask{key: keys[i%len(keys)], count: i}))
+	}
+	for q.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	q.Close()
+	wg.Wait()
+	assert.Greater(t, processed.Load(), int64(len(keys)-1))
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(context.Background(), dedupTask{key: "a"}))
+}
+
+func TestDedupQueue_AddAfter(t *testing.T) {
+	q := NewDedupQueue[dedupTask, string](dedupTaskKey, nil)
+	start := time.Now()
+	q.AddAfter(dedupTask{key: "a"}, time.Millisecond*20)
+	q.AddAfter(dedupTask{key: "b"}, 0)
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "b", val.key)
+	val, err = q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", val.key)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*20)
+
+	// 关闭之后还没有到期的元素被丢弃
+	q.AddAfter(dedupTask{key: "c"}, time.Millisecond)
+	q.Close()
+	time.Sleep(time.Millisecond * 5)
+	_, err = q.Dequeue(context.Background())
+	assert.Equal(t, ErrQueueClosed, err)
+	q.Close()
+}
+
+func TestDedupQueue_AddRateLimited(t *testing.T) {
+	q := NewDedupQueueWithBackoff[dedupTask, string](dedupTaskKey, nil, time.Millisecond, time.Millisecond*6)
+	testCases := []struct {
+		n    int
+		want time.Duration
+	}{
+		{n: 0, want: time.Millisecond},
+		{n: 1, want: time.Millisecond * 2},
+		{n: 2, want: time.Millisecond * 4},
+		{n: 3, want: time.Millisecond * 6},
+		{n: 100, want: time.Millisecond * 6},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, q.backoff(tc.n), "第 %d 次重试", tc.n+1)
+	}
+
+	task := dedupTask{key: "a"}
+	for i := 0; i < 3; i++ {
+		q.AddRateLimited(task)
+		val, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		q.Done(val)
+	}
+	assert.Equal(t, 3, q.NumRequeues(task))
+	q.Forget(task)
+	assert.Equal(t, 0, q.NumRequeues(task))
+}
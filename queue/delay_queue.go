@@ -1 +1,124 @@
 package queue
+
+import (
+	"context"
+	"github.com/zmsocc/generic/internal/queue"
+	"golang.org/x/sync/semaphore"
+	"sync"
+	"time"
+)
+
+var _ BlockingQueue[Delayable] = &DelayQueue[Delayable]{}
+
+// Delayable 延时队列中的元素
+type Delayable interface {
+	// Delay 返回距离到期还剩多少时间，小于等于 0 表示已经到期
+	Delay() time.Duration
+}
+
+// delayElem 入队时把 Delay 换算成绝对的到期时间，堆按照到期时间排序
+type delayElem[T Delayable] struct {
+	val      T
+	deadline time.Time
+}
+
+// DelayQueue 有界并发延时队列
+// 只有到期的元素才能出队，队头永远是最早到期的元素
+type DelayQueue[T Delayable] struct {
+	q             *queue.PriorityQueue[delayElem[T]]
+	mutex         *sync.Mutex
+	enqueueCap    *semaphore.Weighted
+	enqueueSignal *cond // 有元素入队时广播，唤醒等待中的出队者
+	zero          T
+}
+
+// NewDelayQueue 创建一个有界延时队列
+// capacity 必须为正数
+func NewDelayQueue[T Delayable](capacity int) *DelayQueue[T] {
+	m := &sync.Mutex{}
+	return &DelayQueue[T]{
+		q: queue.NewPriorityQueue[delayElem[T]](capacity, func(src delayElem[T], dst delayElem[T]) int {
+			return src.deadline.Compare(dst.deadline)
+		}),
+		mutex:         m,
+		enqueueCap:    semaphore.NewWeighted(int64(capacity)),
+		enqueueSignal: newCond(m),
+	}
+}
+
+// Enqueue 入队
+// 队列满的时候会阻塞，直到有元素出队或者 ctx 过期
+func (d *DelayQueue[T]) Enqueue(ctx context.Context, t T) error {
+	err := d.enqueueCap.Acquire(ctx, 1) // 能拿到，说明队列还有空位
+	if err != nil {
+		return err
+	}
+	d.mutex.Lock()
+	if ctx.Err() != nil { // 拿到锁，先判断是否超时，防止在抢锁时已经超时
+		d.mutex.Unlock()
+		d.enqueueCap.Release(1) // 超时应该主动归还信号量，避免容量泄露
+		return ctx.Err()
+	}
+	err = d.q.Enqueue(delayElem[T]{val: t, deadline: time.Now().Add(t.Delay())})
+	if err != nil {
+		d.mutex.Unlock()
+		d.enqueueCap.Release(1)
+		return err
+	}
+	// 新元素可能比原本的队头更早到期，唤醒出队者重新计算等待时间
+	d.enqueueSignal.broadcast()
+	return nil
+}
+
+// Dequeue 出队
+// 队列为空或者队头元素还没到期的时候会阻塞，直到队头元素到期或者 ctx 过期
+func (d *DelayQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		if ctx.Err() != nil {
+			return d.zero, ctx.Err()
+		}
+		d.mutex.Lock()
+		head, err := d.q.Peek()
+		if err != nil { // 队列为空，等待入队
+			signal := d.enqueueSignal.signalCh()
+			select {
+			case <-ctx.Done():
+				return d.zero, ctx.Err()
+			case <-signal:
+			}
+			continue
+		}
+		delay := time.Until(head.deadline)
+		if delay <= 0 { // 已经到期，可以出队
+			elem, _ := d.q.Dequeue()
+			d.mutex.Unlock()
+			d.enqueueCap.Release(1) // 腾出一个空位，入队的 goroutine 可以拿到并入队
+			return elem.val, nil
+		}
+		signal := d.enqueueSignal.signalCh()
+		if timer == nil {
+			timer = time.NewTimer(delay)
+		} else {
+			timer.Reset(delay)
+		}
+		select {
+		case <-ctx.Done():
+			return d.zero, ctx.Err()
+		case <-timer.C: // 队头到期，回到循环开头重新检查
+		case <-signal: // 有新元素入队，可能比队头更早到期
+		}
+	}
+}
+
+// Len 返回队列中的元素个数，包括还没到期的元素
+func (d *DelayQueue[T]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.q.Len()
+}
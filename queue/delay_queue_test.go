@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type delayItem struct {
+	val      int
+	deadline time.Time
+}
+
+func (d delayItem) Delay() time.Duration {
+	return time.Until(d.deadline)
+}
+
+func newDelayItem(val int, delay time.Duration) delayItem {
+	return delayItem{val: val, deadline: time.Now().Add(delay)}
+}
+
+func TestDelayQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		queue   func() *DelayQueue[delayItem]
+		timeout time.Duration
+		val     delayItem
+		wantErr error
+		wantLen int
+	}{
+		{
+			name: "empty and enqueued",
+			queue: func() *DelayQueue[delayItem] {
+				return NewDelayQueue[delayItem](3)
+			},
+			timeout: time.Second,
+			val:     newDelayItem(1, time.Minute),
+			wantLen: 1,
+		},
+		{
+			name: "invalid context",
+			queue: func() *DelayQueue[delayItem] {
+				return NewDelayQueue[delayItem](3)
+			},
+			timeout: -time.Second,
+			val:     newDelayItem(1, time.Minute),
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			// 队列已满，阻塞直到超时
+			name: "full and timeout",
+			queue: func() *DelayQueue[delayItem] {
+				q := NewDelayQueue[delayItem](2)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				require.NoError(t, q.Enqueue(ctx, newDelayItem(1, time.Minute)))
+				require.NoError(t, q.Enqueue(ctx, newDelayItem(2, time.Minute)))
+				return q
+			},
+			timeout: time.Millisecond * 100,
+			val:     newDelayItem(3, time.Minute),
+			wantErr: context.DeadlineExceeded,
+			wantLen: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.queue()
+			err := q.Enqueue(ctx, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+		})
+	}
+	// 队列已满，出队之后入队成功
+	t.Run("enqueue blocking and dequeue", func(t *testing.T) {
+		q := NewDelayQueue[delayItem](1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, newDelayItem(1, time.Millisecond*10)))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			val, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, val.val)
+		}()
+		err := q.Enqueue(ctx, newDelayItem(2, time.Millisecond*10))
+		require.NoError(t, err)
+		assert.Equal(t, 1, q.Len())
+	})
+}
+
+func TestDelayQueue_Dequeue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		queue   func() *DelayQueue[delayItem]
+		timeout time.Duration
+		wantVal int
+		wantErr error
+	}{
+		{
+			name: "empty and timeout",
+			queue: func() *DelayQueue[delayItem] {
+				return NewDelayQueue[delayItem](3)
+			},
+			timeout: time.Millisecond * 100,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "invalid context",
+			queue: func() *DelayQueue[delayItem] {
+				q := NewDelayQueue[delayItem](3)
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, -time.Second)))
+				return q
+			},
+			timeout: -time.Second,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "already expired",
+			queue: func() *DelayQueue[delayItem] {
+				q := NewDelayQueue[delayItem](3)
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, -time.Second)))
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 1,
+		},
+		{
+			// 队头要很久才到期，出队超时
+			name: "not expired and timeout",
+			queue: func() *DelayQueue[delayItem] {
+				q := NewDelayQueue[delayItem](3)
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, time.Minute)))
+				return q
+			},
+			timeout: time.Millisecond * 100,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "wait until expired",
+			queue: func() *DelayQueue[delayItem] {
+				q := NewDelayQueue[delayItem](3)
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, time.Millisecond*100)))
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 1,
+		},
+		{
+			// 出队顺序和入队顺序无关，只看到期时间
+			name: "earliest deadline first",
+			queue: func() *DelayQueue[delayItem] {
+				q := NewDelayQueue[delayItem](3)
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, time.Millisecond*300)))
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(2, time.Millisecond*50)))
+				require.NoError(t, q.Enqueue(context.Background(), newDelayItem(3, time.Millisecond*200)))
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.queue()
+			val, err := q.Dequeue(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val.val)
+		})
+	}
+	// 出队者在等待一个很晚到期的元素，这时候入队一个更早到期的元素，出队者应该被唤醒
+	t.Run("earlier element wakes dequeue", func(t *testing.T) {
+		q := NewDelayQueue[delayItem](3)
+		require.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, time.Minute)))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, q.Enqueue(context.Background(), newDelayItem(2, time.Millisecond*50)))
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, val.val)
+		assert.Equal(t, 1, q.Len())
+	})
+	// 队列为空，出队者阻塞，入队之后被唤醒
+	t.Run("empty and enqueue", func(t *testing.T) {
+		q := NewDelayQueue[delayItem](3)
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, q.Enqueue(context.Background(), newDelayItem(1, time.Millisecond*50)))
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, val.val)
+	})
+}
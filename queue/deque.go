@@ -0,0 +1,160 @@
+package queue
+
+import (
+	"github.com/zmsocc/generic/internal/errs"
+	"github.com/zmsocc/generic/internal/slice"
+)
+
+const defaultDequeCapacity = 8
+
+var _ Queue[any] = &Deque[any]{}
+
+// Deque 基于环形缓冲区的双端队列，不是并发安全的
+// 容量不够的时候会自动扩容，元素减少之后会按照 slice.Shrink 的规则缩容
+type Deque[T any] struct {
+	data  []T // 环形缓冲区，len(data) == cap(data)
+	head  int // 队头元素下标
+	count int // 包含多少元素
+	zero  T
+}
+
+// NewDeque 创建一个双端队列，capacity 是初始容量
+// capacity <= 0 的时候使用默认容量
+func NewDeque[T any](capacity int) *Deque[T] {
+	if capacity <= 0 {
+		capacity = defaultDequeCapacity
+	}
+	return &Deque[T]{
+		data: make([]T, capacity),
+	}
+}
+
+// Enqueue 在队尾放入元素，永远不会返回 error，和 PushBack 相同
+func (d *Deque[T]) Enqueue(t T) error {
+	d.PushBack(t)
+	return nil
+}
+
+// Dequeue 取出队头元素，和 PopFront 相同
+func (d *Deque[T]) Dequeue() (T, error) {
+	return d.PopFront()
+}
+
+// PushFront 在队头放入元素
+func (d *Deque[T]) PushFront(t T) {
+	d.growIfNecessary()
+	d.head = d.index(-1)
+	d.data[d.head] = t
+	d.count++
+}
+
+// PushBack 在队尾放入元素
+func (d *Deque[T]) PushBack(t T) {
+	d.growIfNecessary()
+	d.data[d.index(d.count)] = t
+	d.count++
+}
+
+// PopFront 取出队头元素，队列为空的时候返回 ErrEmptyQueue
+func (d *Deque[T]) PopFront() (T, error) {
+	if d.count == 0 {
+		return d.zero, ErrEmptyQueue
+	}
+	res := d.data[d.head]
+	d.data[d.head] = d.zero // 为了释放内存，GC
+	d.head = d.index(1)
+	d.count--
+	d.shrinkIfNecessary()
+	return res, nil
+}
+
+// PopBack 取出队尾元素，队列为空的时候返回 ErrEmptyQueue
+func (d *Deque[T]) PopBack() (T, error) {
+	if d.count == 0 {
+		return d.zero, ErrEmptyQueue
+	}
+	tail := d.index(d.count - 1)
+	res := d.data[tail]
+	d.data[tail] = d.zero
+	d.count--
+	d.shrinkIfNecessary()
+	return res, nil
+}
+
+// PeekFront 返回队头元素但不出队，队列为空的时候返回 ErrEmptyQueue
+func (d *Deque[T]) PeekFront() (T, error) {
+	if d.count == 0 {
+		return d.zero, ErrEmptyQueue
+	}
+	return d.data[d.head], nil
+}
+
+// PeekBack 返回队尾元素但不出队，队列为空的时候返回 ErrEmptyQueue
+func (d *Deque[T]) PeekBack() (T, error) {
+	if d.count == 0 {
+		return d.zero, ErrEmptyQueue
+	}
+	return d.data[d.index(d.count-1)], nil
+}
+
+// Get 返回从队头开始第 index 个元素
+func (d *Deque[T]) Get(index int) (T, error) {
+	if index < 0 || index >= d.count {
+		return d.zero, errs.NewErrIndexOutOfRange(d.count-1, index)
+	}
+	return d.data[d.index(index)], nil
+}
+
+func (d *Deque[T]) Len() int {
+	return d.count
+}
+
+func (d *Deque[T]) Cap() int {
+	return len(d.data)
+}
+
+// AsSlice 按照从队头到队尾的顺序返回元素的副本
+func (d *Deque[T]) AsSlice() []T {
+	res := make([]T, d.count)
+	d.copyTo(res)
+	return res
+}
+
+// index 返回从队头开始第 i 个元素在环形缓冲区中的下标，i 可以为 -1
+func (d *Deque[T]) index(i int) int {
+	capacity := len(d.data)
+	return (d.head + i + capacity) % capacity
+}
+
+// copyTo 按照逻辑顺序把元素复制到 dst，dst 的长度至少为 d.count
+func (d *Deque[T]) copyTo(dst []T) {
+	if d.head+d.count <= len(d.data) {
+		copy(dst, d.data[d.head:d.head+d.count])
+		return
+	}
+	n := copy(dst, d.data[d.head:])
+	copy(dst[n:], d.data[:d.count-n])
+}
+
+func (d *Deque[T]) growIfNecessary() {
+	if d.count < len(d.data) {
+		return
+	}
+	data := make([]T, len(d.data)*2)
+	d.copyTo(data)
+	d.data = data
+	d.head = 0
+}
+
+// shrinkIfNecessary 复用 slice.Shrink 的缩容规则
+// 不需要缩容的时候不会产生任何复制，需要缩容的时候和 growIfNecessary 一样只分配和复制一次
+func (d *Deque[T]) shrinkIfNecessary() {
+	n, changed := slice.ShrinkCapacity(len(d.data), d.count)
+	if !changed {
+		return
+	}
+	data := make([]T, n)
+	d.copyTo(data)
+	d.data = data
+	d.head = 0
+}
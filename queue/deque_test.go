@@ -0,0 +1,259 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic/internal/errs"
+	"testing"
+)
+
+func TestDeque_Push(t *testing.T) {
+	testCases := []struct {
+		name      string
+		deque     func() *Deque[int]
+		push      func(d *Deque[int])
+		wantSlice []int
+		wantCap   int
+	}{
+		{
+			name: "push back",
+			deque: func() *Deque[int] {
+				return NewDeque[int](4)
+			},
+			push: func(d *Deque[int]) {
+				d.PushBack(1)
+				d.PushBack(2)
+			},
+			wantSlice: []int{1, 2},
+			wantCap:   4,
+		},
+		{
+			name: "push front",
+			deque: func() *Deque[int] {
+				return NewDeque[int](4)
+			},
+			push: func(d *Deque[int]) {
+				d.PushFront(1)
+				d.PushFront(2)
+			},
+			wantSlice: []int{2, 1},
+			wantCap:   4,
+		},
+		{
+			// 头部插入之后环绕到缓冲区末尾，再扩容
+			name: "wrap and grow",
+			deque: func() *Deque[int] {
+				return NewDeque[int](4)
+			},
+			push: func(d *Deque[int]) {
+				d.PushBack(3)
+				d.PushBack(4)
+				d.PushFront(2)
+				d.PushFront(1)
+				d.PushBack(5)
+				d.PushFront(0)
+			},
+			wantSlice: []int{0, 1, 2, 3, 4, 5},
+			wantCap:   8,
+		},
+		{
+			name: "default capacity",
+			deque: func() *Deque[int] {
+				return NewDeque[int](0)
+			},
+			push: func(d *Deque[int]) {
+				d.PushBack(1)
+			},
+			wantSlice: []int{1},
+			wantCap:   defaultDequeCapacity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := tc.deque()
+			tc.push(d)
+			assert.Equal(t, tc.wantSlice, d.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), d.Len())
+			assert.Equal(t, tc.wantCap, d.Cap())
+		})
+	}
+}
+
+func TestDeque_Pop(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      []int
+		front     bool
+		wantVal   int
+		wantErr   error
+		wantSlice []int
+	}{
+		{
+			name:      "pop front empty",
+			data:      []int{},
+			front:     true,
+			wantErr:   ErrEmptyQueue,
+			wantSlice: []int{},
+		},
+		{
+			name:      "pop back empty",
+			data:      []int{},
+			wantErr:   ErrEmptyQueue,
+			wantSlice: []int{},
+		},
+		{
+			name:      "pop front",
+			data:      []int{1, 2, 3},
+			front:     true,
+			wantVal:   1,
+			wantSlice: []int{2, 3},
+		},
+		{
+			name:      "pop back",
+			data:      []int{1, 2, 3},
+			wantVal:   3,
+			wantSlice: []int{1, 2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := dequeOf(tc.data)
+			var val int
+			var err error
+			if tc.front {
+				val, err = d.PopFront()
+			} else {
+				val, err = d.PopBack()
+			}
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, d.AsSlice())
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+}
+
+func TestDeque_Peek(t *testing.T) {
+	d := NewDeque[int](2)
+	_, err := d.PeekFront()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = d.PeekBack()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	d.PushFront(2)
+	d.PushFront(1)
+	d.PushBack(3)
+	front, err := d.PeekFront()
+	require.NoError(t, err)
+	assert.Equal(t, 1, front)
+	back, err := d.PeekBack()
+	require.NoError(t, err)
+	assert.Equal(t, 3, back)
+	assert.Equal(t, 3, d.Len())
+}
+
+func TestDeque_Get(t *testing.T) {
+	testCases := []struct {
+		name    string
+		index   int
+		wantVal int
+		wantErr error
+	}{
+		{
+			name:    "first",
+			index:   0,
+			wantVal: 1,
+		},
+		{
+			name:    "last",
+			index:   3,
+			wantVal: 4,
+		},
+		{
+			name:    "negative",
+			index:   -1,
+			wantErr: errs.NewErrIndexOutOfRange(3, -1),
+		},
+		{
+			name:    "out of range",
+			index:   4,
+			wantErr: errs.NewErrIndexOutOfRange(3, 4),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 元素环绕在缓冲区的首尾
+			d := NewDeque[int](4)
+			d.PushBack(3)
+			d.PushBack(4)
+			d.PushFront(2)
+			d.PushFront(1)
+			val, err := d.Get(tc.index)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+}
+
+func TestDeque_Shrink(t *testing.T) {
+	testCases := []struct {
+		name    string
+		push    int
+		pop     int
+		wantCap int
+	}{
+		{
+			name:    "less than 64",
+			push:    64,
+			pop:     60,
+			wantCap: 64,
+		},
+		{
+			name:    "between 64 and 2048",
+			push:    512,
+			pop:     400,
+			wantCap: 256,
+		},
+		{
+			name:    "between 64 and 2048 not shrink",
+			push:    512,
+			pop:     300,
+			wantCap: 512,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDeque[int](tc.push)
+			// 先从头部放一半，制造环绕
+			for i := tc.push/2 - 1; i >= 0; i-- {
+				d.PushFront(i)
+			}
+			for i := tc.push / 2; i < tc.push; i++ {
+				d.PushBack(i)
+			}
+			for i := 0; i < tc.pop; i++ {
+				val, err := d.PopFront()
+				require.NoError(t, err)
+				require.Equal(t, i, val)
+			}
+			assert.Equal(t, tc.wantCap, d.Cap())
+			res := d.AsSlice()
+			for i, v := range res {
+				assert.Equal(t, tc.pop+i, v)
+			}
+		})
+	}
+}
+
+func dequeOf(data []int) *Deque[int] {
+	d := NewDeque[int](len(data))
+	for _, v := range data {
+		d.PushBack(v)
+	}
+	return d
+}
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"github.com/zmsocc/generic"
+	"github.com/zmsocc/generic/internal/queue"
+)
+
+// Handle 入队时返回的句柄，用于之后修改或者删除该元素
+type Handle[T any] = queue.Handle[T]
+
+// IndexedPriorityQueue 带索引的优先级队列，不是并发安全的
+// Update、Remove 和 Contains 都是 O(log n) 或者 O(1) 的，适用于 Dijkstra、A* 等需要 decrease-key 的场景
+type IndexedPriorityQueue[T any] struct {
+	q *queue.IndexedPriorityQueue[T]
+}
+
+// NewIndexedPriorityQueue 创建一个带索引的优先级队列
+// capacity <= 0 表示无界队列
+func NewIndexedPriorityQueue[T any](capacity int, compare generic.Comparator[T]) *IndexedPriorityQueue[T] {
+	return &IndexedPriorityQueue[T]{
+		q: queue.NewIndexedPriorityQueue[T](capacity, compare),
+	}
+}
+
+// Enqueue 入队，返回元素对应的句柄
+func (p *IndexedPriorityQueue[T]) Enqueue(t T) (*Handle[T], error) {
+	return p.q.Enqueue(t)
+}
+
+func (p *IndexedPriorityQueue[T]) Dequeue() (T, error) {
+	return p.q.Dequeue()
+}
+
+func (p *IndexedPriorityQueue[T]) Peek() (T, error) {
+	return p.q.Peek()
+}
+
+// Update 修改句柄对应的元素，句柄已经不在队列中的时候返回 ErrInvalidHandle
+func (p *IndexedPriorityQueue[T]) Update(h *Handle[T], val T) error {
+	return p.q.Update(h, val)
+}
+
+// Remove 删除句柄对应的元素，句柄已经不在队列中的时候返回 ErrInvalidHandle
+func (p *IndexedPriorityQueue[T]) Remove(h *Handle[T]) (T, error) {
+	return p.q.Remove(h)
+}
+
+// Contains 判断句柄对应的元素是否还在队列中
+func (p *IndexedPriorityQueue[T]) Contains(h *Handle[T]) bool {
+	return p.q.Contains(h)
+}
+
+func (p *IndexedPriorityQueue[T]) Len() int {
+	return p.q.Len()
+}
+
+func (p *IndexedPriorityQueue[T]) Cap() int {
+	return p.q.Cap()
+}
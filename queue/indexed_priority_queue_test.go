@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic"
+	"testing"
+)
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	q := NewIndexedPriorityQueue[int](3, generic.ComparatorOrdered[int])
+	h1, err := q.Enqueue(3)
+	require.NoError(t, err)
+	h2, err := q.Enqueue(2)
+	require.NoError(t, err)
+	h3, err := q.Enqueue(1)
+	require.NoError(t, err)
+	_, err = q.Enqueue(4)
+	assert.Equal(t, ErrOutOfCapacity, err)
+	assert.Equal(t, 3, q.Len())
+	assert.Equal(t, 3, q.Cap())
+
+	// decrease-key
+	require.NoError(t, q.Update(h1, 0))
+	peek, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 0, peek)
+
+	val, err := q.Remove(h2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+	assert.False(t, q.Contains(h2))
+	assert.Equal(t, ErrInvalidHandle, q.Update(h2, 5))
+
+	val, err = q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 0, val)
+	val, err = q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.False(t, q.Contains(h3))
+	_, err = q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+}
@@ -0,0 +1,101 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+var _ Queue[any] = &MPMCQueue[any]{}
+
+// mpmcSlot 队列中的一个位置
+// seq 记录该位置当前的状态：seq == pos 表示可以写入 pos，seq == pos + 1 表示 pos 已经写入，可以读取
+type mpmcSlot[T any] struct {
+	seq atomic.Uint64
+	val T
+}
+
+// MPMCQueue 多生产者多消费者的有界无锁队列，基于 Dmitry Vyukov 的算法实现
+// 每个位置都带有一个序号，生产者和消费者通过 CAS 抢占位置，通过序号判断位置是否可用
+// 队列满的时候入队返回 ErrOutOfCapacity，队列为空的时候出队返回 ErrEmptyQueue
+type MPMCQueue[T any] struct {
+	_          cacheLinePad
+	enqueuePos atomic.Uint64
+	_          cacheLinePad
+	dequeuePos atomic.Uint64
+	_          cacheLinePad
+	mask       uint64
+	slots      []mpmcSlot[T]
+}
+
+// NewMPMCQueue 创建一个多生产者多消费者队列
+// capacity 必须为正数，并且会被向上取整为 2 的幂
+func NewMPMCQueue[T any](capacity int) *MPMCQueue[T] {
+	c := roundUpToPowerOfTwo(capacity)
+	slots := make([]mpmcSlot[T], c)
+	for i := range slots {
+		slots[i].seq.Store(uint64(i))
+	}
+	return &MPMCQueue[T]{
+		mask:  c - 1,
+		slots: slots,
+	}
+}
+
+func (q *MPMCQueue[T]) Enqueue(t T) error {
+	pos := q.enqueuePos.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		diff := int64(slot.seq.Load()) - int64(pos)
+		switch {
+		case diff == 0: // 该位置可以写入，尝试抢占
+			if q.enqueuePos.CompareAndSwap(pos, pos+1) {
+				slot.val = t
+				slot.seq.Store(pos + 1)
+				return nil
+			}
+			pos = q.enqueuePos.Load()
+		case diff < 0: // 该位置上一轮的元素还没被取走，队列已满
+			return ErrOutOfCapacity
+		default: // 被别的生产者抢先了
+			pos = q.enqueuePos.Load()
+		}
+	}
+}
+
+func (q *MPMCQueue[T]) Dequeue() (T, error) {
+	pos := q.dequeuePos.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		diff := int64(slot.seq.Load()) - int64(pos+1)
+		switch {
+		case diff == 0: // 该位置已经写入，尝试抢占
+			if q.dequeuePos.CompareAndSwap(pos, pos+1) {
+				res := slot.val
+				var zero T
+				slot.val = zero // 为了释放内存，GC
+				// 留给下一轮的生产者
+				slot.seq.Store(pos + q.mask + 1)
+				return res, nil
+			}
+			pos = q.dequeuePos.Load()
+		case diff < 0: // 该位置还没写入，队列为空
+			var zero T
+			return zero, ErrEmptyQueue
+		default: // 被别的消费者抢先了
+			pos = q.dequeuePos.Load()
+		}
+	}
+}
+
+// Len 返回队列长度，在并发入队出队的时候只是一个近似值
+func (q *MPMCQueue[T]) Len() int {
+	dequeuePos := q.dequeuePos.Load()
+	enqueuePos := q.enqueuePos.Load()
+	if enqueuePos < dequeuePos {
+		return 0
+	}
+	return int(enqueuePos - dequeuePos)
+}
+
+func (q *MPMCQueue[T]) Cap() int {
+	return len(q.slots)
+}
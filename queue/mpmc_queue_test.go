@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestMPMCQueue_EnqueueDequeue(t *testing.T) {
+	q := NewMPMCQueue[int](3)
+	assert.Equal(t, 4, q.Cap())
+	_, err := q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+	// 多轮入队出队，下标会环绕
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			require.NoError(t, q.Enqueue(round*10+i))
+		}
+		assert.Equal(t, ErrOutOfCapacity, q.Enqueue(100))
+		assert.Equal(t, 4, q.Len())
+		for i := 0; i < 4; i++ {
+			val, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, round*10+i, val)
+		}
+		_, err = q.Dequeue()
+		assert.Equal(t, ErrEmptyQueue, err)
+		assert.Equal(t, 0, q.Len())
+	}
+}
+
+// 多个生产者和消费者并发，所有元素都只被消费一次
+func TestMPMCQueue_Concurrent(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 5000
+	q := NewMPMCQueue[int](64)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; {
+				if q.Enqueue(p*perProducer+i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	var mutex sync.Mutex
+	res := make([]int, 0, producers*perProducer)
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < producers*perProducer/consumers; {
+				val, err := q.Dequeue()
+				if err == ErrEmptyQueue {
+					runtime.Gosched()
+					continue
+				}
+				i++
+				mutex.Lock()
+				res = append(res, val)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Ints(res)
+	for i, v := range res {
+		require.Equal(t, i, v)
+	}
+}
+
+// 多个 goroutine 同时入队出队，对比 MPMCQueue、ConcurrentArrayBlockingQueue 和 channel
+func BenchmarkMPMCQueue(b *testing.B) {
+	b.Run("MPMCQueue", func(b *testing.B) {
+		q := NewMPMCQueue[int](1024)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				for q.Enqueue(1) != nil {
+					runtime.Gosched()
+				}
+				for {
+					if _, err := q.Dequeue(); err == nil {
+						break
+					}
+					runtime.Gosched()
+				}
+			}
+		})
+	})
+	b.Run("ConcurrentArrayBlockingQueue", func(b *testing.B) {
+		q := NewConcurrentArrayBlockingQueue[int](1024)
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = q.Enqueue(ctx, 1)
+				_, _ = q.Dequeue(ctx)
+			}
+		})
+	})
+	b.Run("channel", func(b *testing.B) {
+		ch := make(chan int, 1024)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				ch <- 1
+				<-ch
+			}
+		})
+	})
+}
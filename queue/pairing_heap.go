@@ -0,0 +1,215 @@
+package queue
+
+import (
+	"github.com/zmsocc/generic"
+)
+
+// PairingHeapNode 配对堆中的结点，Insert 的时候返回，用于之后 DecreaseKey 或者 Delete
+type PairingHeapNode[T any] struct {
+	val     T
+	child   *PairingHeapNode[T] // 最左边的子结点
+	sibling *PairingHeapNode[T] // 右边的兄弟结点
+	// prev 如果是最左边的子结点，指向父结点，否则指向左边的兄弟结点
+	prev  *PairingHeapNode[T]
+	owner *heapOwner // 结点被删除之后为 nil
+}
+
+// Value 返回结点当前的值
+func (n *PairingHeapNode[T]) Value() T {
+	return n.val
+}
+
+// heapOwner 标识结点属于哪个堆
+// Meld 之后被合并的堆的 owner 指向合并之后的堆的 owner，这样 Meld 不需要修改每一个结点，
+// 查找的时候顺便压缩路径，类似于并查集
+type heapOwner struct {
+	parent *heapOwner
+}
+
+func (o *heapOwner) find() *heapOwner {
+	root := o
+	for root.parent != nil {
+		root = root.parent
+	}
+	for o != root {
+		next := o.parent
+		o.parent = root
+		o = next
+	}
+	return root
+}
+
+// PairingHeap 基于指针的配对堆（最小堆），不是并发安全的
+// Insert、Min 和 Meld 都是 O(1) 的，ExtractMin 均摊 O(log n)，DecreaseKey 均摊 o(log n)
+// 适合 Dijkstra、Prim 等需要频繁 decrease-key，或者需要合并多个堆的场景
+type PairingHeap[T any] struct {
+	compare generic.Comparator[T]
+	root    *PairingHeapNode[T]
+	size    int
+	owner   *heapOwner
+	zero    T
+}
+
+// NewPairingHeap 创建一个配对堆
+func NewPairingHeap[T any](compare generic.Comparator[T]) *PairingHeap[T] {
+	return &PairingHeap[T]{
+		compare: compare,
+		owner:   &heapOwner{},
+	}
+}
+
+// Insert 插入元素，返回元素对应的结点
+func (h *PairingHeap[T]) Insert(t T) *PairingHeapNode[T] {
+	n := &PairingHeapNode[T]{val: t, owner: h.owner}
+	h.root = h.link(h.root, n)
+	h.size++
+	return n
+}
+
+// Min 返回最小的元素，堆为空的时候返回 ErrEmptyQueue
+func (h *PairingHeap[T]) Min() (T, error) {
+	if h.root == nil {
+		return h.zero, ErrEmptyQueue
+	}
+	return h.root.val, nil
+}
+
+// ExtractMin 删除并返回最小的元素，堆为空的时候返回 ErrEmptyQueue
+func (h *PairingHeap[T]) ExtractMin() (T, error) {
+	if h.root == nil {
+		return h.zero, ErrEmptyQueue
+	}
+	root := h.root
+	h.root = h.mergePairs(root.child)
+	h.size--
+	root.child, root.owner = nil, nil
+	return root.val, nil
+}
+
+// DecreaseKey 把结点 n 的值修改为 t
+// n 不属于这个堆，或者已经被删除的时候返回 ErrInvalidHandle
+// t 应当不大于结点原来的值；如果 t 更大，会退化为先删除再插入，依旧能保证正确性
+func (h *PairingHeap[T]) DecreaseKey(n *PairingHeapNode[T], t T) error {
+	if !h.contains(n) {
+		return ErrInvalidHandle
+	}
+	if h.compare(t, n.val) > 0 {
+		h.delete(n)
+		n.val = t
+		n.owner = h.owner
+		h.root = h.link(h.root, n)
+		h.size++
+		return nil
+	}
+	n.val = t
+	if n == h.root {
+		return nil
+	}
+	h.cut(n)
+	h.root = h.link(h.root, n)
+	return nil
+}
+
+// Delete 删除结点 n，n 不属于这个堆，或者已经被删除的时候返回 ErrInvalidHandle
+func (h *PairingHeap[T]) Delete(n *PairingHeapNode[T]) error {
+	if !h.contains(n) {
+		return ErrInvalidHandle
+	}
+	h.delete(n)
+	return nil
+}
+
+// Meld 把 other 中的所有元素合并到 h 中，时间复杂度为 O(1)
+// 合并之后 other 为空，other 中的结点属于 h，可以继续在 h 上调用 DecreaseKey
+func (h *PairingHeap[T]) Meld(other *PairingHeap[T]) {
+	if other == h || other.root == nil {
+		return
+	}
+	h.root = h.link(h.root, other.root)
+	h.size += other.size
+	other.owner.find().parent = h.owner.find()
+	other.root, other.size = nil, 0
+	// other 之后插入的结点不能再和合并进来的结点混在一起
+	other.owner = &heapOwner{}
+}
+
+func (h *PairingHeap[T]) Len() int {
+	return h.size
+}
+
+func (h *PairingHeap[T]) contains(n *PairingHeapNode[T]) bool {
+	return n != nil && n.owner != nil && n.owner.find() == h.owner.find()
+}
+
+// delete 删除结点 n，调用方保证 n 属于这个堆
+func (h *PairingHeap[T]) delete(n *PairingHeapNode[T]) {
+	if n == h.root {
+		_, _ = h.ExtractMin()
+		return
+	}
+	h.cut(n)
+	h.root = h.link(h.root, h.mergePairs(n.child))
+	h.size--
+	n.child, n.owner = nil, nil
+}
+
+// link 合并两棵树，值较大的根成为值较小的根最左边的子结点，返回新的根
+func (h *PairingHeap[T]) link(a, b *PairingHeapNode[T]) *PairingHeapNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.compare(b.val, a.val) < 0 {
+		a, b = b, a
+	}
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	b.prev = a
+	a.child = b
+	a.sibling, a.prev = nil, nil
+	return a
+}
+
+// cut 把以 n 为根的子树从树中摘下来
+func (h *PairingHeap[T]) cut(n *PairingHeapNode[T]) {
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+}
+
+// mergePairs 两趟合并：先从左到右两两合并，再从右到左依次合并，返回新的根
+func (h *PairingHeap[T]) mergePairs(first *PairingHeapNode[T]) *PairingHeapNode[T] {
+	if first == nil {
+		return nil
+	}
+	var pairs []*PairingHeapNode[T]
+	for first != nil {
+		a := first
+		b := a.sibling
+		if b == nil {
+			first = nil
+		} else {
+			first = b.sibling
+		}
+		a.prev, a.sibling = nil, nil
+		if b != nil {
+			b.prev, b.sibling = nil, nil
+		}
+		pairs = append(pairs, h.link(a, b))
+	}
+	res := pairs[len(pairs)-1]
+	for i := len(pairs) - 2; i >= 0; i-- {
+		res = h.link(pairs[i], res)
+	}
+	return res
+}
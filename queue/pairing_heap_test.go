@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic"
+	"golang.org/x/exp/slices"
+	"math/rand"
+	"testing"
+)
+
+func TestPairingHeap(t *testing.T) {
+	h := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	_, err := h.Min()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = h.ExtractMin()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	for _, v := range []int{5, 3, 8, 1, 9, 2, 7} {
+		h.Insert(v)
+	}
+	assert.Equal(t, 7, h.Len())
+	val, err := h.Min()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, []int{1, 2, 3, 5, 7, 8, 9}, drainPairingHeap(t, h))
+	assert.Equal(t, 0, h.Len())
+}
+
+func TestPairingHeap_DecreaseKey(t *testing.T) {
+	testCases := []struct {
+		name    string
+		vals    []int
+		index   int // 修改第几个插入的元素
+		newVal  int
+		wantMin int
+		wantRes []int
+	}{
+		{
+			name:    "decrease to min",
+			vals:    []int{5, 3, 8, 1},
+			index:   2,
+			newVal:  0,
+			wantMin: 0,
+			wantRes: []int{0, 1, 3, 5},
+		},
+		{
+			name:    "decrease root",
+			vals:    []int{5, 3, 8, 1},
+			index:   3,
+			newVal:  -1,
+			wantMin: -1,
+			wantRes: []int{-1, 3, 5, 8},
+		},
+		{
+			name:    "decrease but not min",
+			vals:    []int{5, 3, 8, 1},
+			index:   2,
+			newVal:  4,
+			wantMin: 1,
+			wantRes: []int{1, 3, 4, 5},
+		},
+		{
+			// 值变大的时候先删除再插入
+			name:    "increase",
+			vals:    []int{5, 3, 8, 1},
+			index:   3,
+			newVal:  6,
+			wantMin: 3,
+			wantRes: []int{3, 5, 6, 8},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewPairingHeap[int](generic.ComparatorOrdered[int])
+			nodes := make([]*PairingHeapNode[int], 0, len(tc.vals))
+			for _, v := range tc.vals {
+				nodes = append(nodes, h.Insert(v))
+			}
+			// 先出队一次再放回去，让堆的结构不只是一层
+			val, err := h.ExtractMin()
+			require.NoError(t, err)
+			nodes[slices.Index(tc.vals, val)] = h.Insert(val)
+
+			require.NoError(t, h.DecreaseKey(nodes[tc.index], tc.newVal))
+			assert.Equal(t, tc.newVal, nodes[tc.index].Value())
+			assert.Equal(t, len(tc.vals), h.Len())
+			res, err := h.Min()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMin, res)
+			assert.Equal(t, tc.wantRes, drainPairingHeap(t, h))
+		})
+	}
+}
+
+func TestPairingHeap_Delete(t *testing.T) {
+	h := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	nodes := make([]*PairingHeapNode[int], 0, 10)
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, h.Insert(i))
+	}
+	_, err := h.ExtractMin()
+	require.NoError(t, err)
+	// 已经出队的结点是无效的
+	assert.Equal(t, ErrInvalidHandle, h.Delete(nodes[0]))
+	assert.Equal(t, ErrInvalidHandle, h.DecreaseKey(nodes[0], -1))
+	assert.Equal(t, ErrInvalidHandle, h.Delete(nil))
+
+	require.NoError(t, h.Delete(nodes[5]))
+	require.NoError(t, h.Delete(nodes[1]))
+	assert.Equal(t, ErrInvalidHandle, h.Delete(nodes[5]))
+	assert.Equal(t, 7, h.Len())
+	assert.Equal(t, []int{2, 3, 4, 6, 7, 8, 9}, drainPairingHeap(t, h))
+
+	// 其他堆的结点是无效的
+	other := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	n := other.Insert(1)
+	assert.Equal(t, ErrInvalidHandle, h.Delete(n))
+	assert.Equal(t, ErrInvalidHandle, h.DecreaseKey(n, 0))
+}
+
+func TestPairingHeap_Meld(t *testing.T) {
+	h1 := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	h2 := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	h3 := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	h1.Insert(5)
+	h1.Insert(1)
+	n2 := h2.Insert(7)
+	h2.Insert(3)
+	n3 := h3.Insert(9)
+
+	h2.Meld(h3)
+	h1.Meld(h2)
+	h1.Meld(h1)
+	h1.Meld(NewPairingHeap[int](generic.ComparatorOrdered[int]))
+	assert.Equal(t, 5, h1.Len())
+	assert.Equal(t, 0, h2.Len())
+	assert.Equal(t, 0, h3.Len())
+
+	// 合并进来的结点属于 h1，不再属于原来的堆
+	assert.Equal(t, ErrInvalidHandle, h2.DecreaseKey(n2, 0))
+	require.NoError(t, h1.DecreaseKey(n2, 0))
+	require.NoError(t, h1.DecreaseKey(n3, 2))
+
+	// 被合并的堆可以继续使用，和 h1 互不影响
+	n := h2.Insert(4)
+	assert.Equal(t, ErrInvalidHandle, h1.Delete(n))
+	assert.Equal(t, []int{4}, drainPairingHeap(t, h2))
+	assert.Equal(t, []int{0, 1, 2, 3, 5}, drainPairingHeap(t, h1))
+}
+
+// 随机操作，和排序之后的结果对比
+func TestPairingHeap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	h := NewPairingHeap[int](generic.ComparatorOrdered[int])
+	nodes := make(map[*PairingHeapNode[int]]struct{})
+	for i := 0; i < 5000; i++ {
+		switch op := r.Intn(10); {
+		case op < 5:
+			nodes[h.Insert(r.Intn(10000))] = struct{}{}
+		case op < 7:
+			val, err := h.ExtractMin()
+			if err != nil {
+				assert.Equal(t, 0, len(nodes))
+				continue
+			}
+			// 出队的一定是最小值
+			for n := range nodes {
+				require.LessOrEqual(t, val, n.Value())
+			}
+			for n := range nodes {
+				if n.Value() == val && n.owner == nil {
+					delete(nodes, n)
+					break
+				}
+			}
+		default:
+			for n := range nodes {
+				require.NoError(t, h.DecreaseKey(n, n.Value()-r.Intn(100)))
+				break
+			}
+		}
+		require.Equal(t, len(nodes), h.Len())
+	}
+	want := make([]int, 0, len(nodes))
+	for n := range nodes {
+		want = append(want, n.Value())
+	}
+	slices.Sort(want)
+	assert.Equal(t, want, drainPairingHeap(t, h))
+}
+
+func BenchmarkPairingHeap(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	vals := make([]int, 1<<16)
+	for i := range vals {
+		vals[i] = r.Int()
+	}
+	b.Run("pairing heap", func(b *testing.B) {
+		h := NewPairingHeap[int](generic.ComparatorOrdered[int])
+		for i := 0; i < b.N; i++ {
+			h.Insert(vals[i&(len(vals)-1)])
+			if i%2 == 1 {
+				_, _ = h.ExtractMin()
+			}
+		}
+	})
+	b.Run("priority queue", func(b *testing.B) {
+		p := NewPriorityQueue[int](0, generic.ComparatorOrdered[int])
+		for i := 0; i < b.N; i++ {
+			_ = p.Enqueue(vals[i&(len(vals)-1)])
+			if i%2 == 1 {
+				_, _ = p.Dequeue()
+			}
+		}
+	})
+}
+
+func drainPairingHeap[T any](t *testing.T, h *PairingHeap[T]) []T {
+	res := make([]T, 0, h.Len())
+	for h.Len() > 0 {
+		val, err := h.ExtractMin()
+		require.NoError(t, err)
+		res = append(res, val)
+	}
+	return res
+}
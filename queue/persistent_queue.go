@@ -0,0 +1,472 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultSegmentSize = 64 << 20
+	segmentSuffix      = ".seg"
+	offsetFileName     = "offset"
+	recordHeaderSize   = 8 // 4 字节长度 + 4 字节 crc32
+)
+
+// PersistentQueue 基于本地文件的持久化队列，进程重启之后不会丢失数据
+// 元素按顺序追加到分段文件（segment）中，每条记录都带有长度和 crc32 校验
+// 出队不会删除元素，只有 Ack 之后才算消费完成，重启之后从最后一次 Ack 的位置继续出队，
+// 因此提供的是 at-least-once 语义。所有元素都被 Ack 的分段文件会被删除
+//
+// 写入只保证进入操作系统的页缓存，可以扛住进程崩溃；需要扛住断电的时候调用 Sync
+type PersistentQueue[T any] struct {
+	dir         string
+	codec       Codec[T]
+	segmentSize int64
+	mutex       *sync.Mutex
+
+	segments []uint64 // 所有分段文件的起始序号，递增
+
+	writer     segmentWriter
+	writerSize int64
+	writeSeq   uint64 // 下一个入队元素的序号
+
+	reader    *bufio.Reader
+	readFile  *os.File
+	readIdx   int    // 正在读取的分段在 segments 中的下标
+	readSeq   uint64 // 下一个出队元素的序号
+	ackSeq    uint64 // 小于 ackSeq 的元素都已经 Ack
+	closed    bool
+	headerBuf [recordHeaderSize]byte
+}
+
+// errChecksumMismatch 记录的长度是完整的，只是内容校验失败，记录的边界没有被破坏
+var errChecksumMismatch = fmt.Errorf("%w: checksum mismatch", ErrCorruptRecord)
+
+// segmentWriter 正在写入的分段文件，测试的时候可以替换掉，模拟写入失败
+type segmentWriter interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// NewPersistentQueue 打开 dir 下的持久化队列，目录不存在的时候会自动创建
+// 如果目录里已经有数据，会从上一次 Ack 的位置恢复，末尾写了一半的记录会被截断
+// segmentSize 是单个分段文件的大小上限，<= 0 的时候使用默认值 64MB
+func NewPersistentQueue[T any](dir string, segmentSize int64, codec Codec[T]) (*PersistentQueue[T], error) {
+	if segmentSize <= 0 {
+		segmentSize = defaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &PersistentQueue[T]{
+		dir:         dir,
+		codec:       codec,
+		segmentSize: segmentSize,
+		mutex:       &sync.Mutex{},
+	}
+	if err := q.recover(); err != nil {
+		q.closeFiles()
+		return nil, err
+	}
+	return q, nil
+}
+
+// Enqueue 入队
+func (q *PersistentQueue[T]) Enqueue(t T) error {
+	payload, err := q.codec.Encode(t)
+	if err != nil {
+		return err
+	}
+	if int64(len(payload)) > q.maxRecordSize() {
+		return ErrOutOfCapacity
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	record := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSize:], payload)
+	if q.writerSize > 0 && q.writerSize+int64(len(record)) > q.segmentSize {
+		if err = q.roll(); err != nil {
+			return err
+		}
+	}
+	n, err := q.writer.Write(record)
+	if err != nil {
+		if n > 0 {
+			// 写了一半的记录必须撤销，否则之后写入的记录都跟在垃圾数据后面，重启的时候会被截断
+			err = errors.Join(err, q.rollback())
+		}
+		return err
+	}
+	q.writerSize += int64(n)
+	q.writeSeq++
+	return nil
+}
+
+// rollback 把分段截断到 writerSize，丢掉写了一半的记录
+// 截断失败的时候换一个新的分段，旧分段末尾的数据不会被读到：读取的时候按照下一个分段的起始序号切换
+func (q *PersistentQueue[T]) rollback() error {
+	err := q.writer.Truncate(q.writerSize)
+	if err == nil {
+		_, err = q.writer.Seek(q.writerSize, io.SeekStart)
+	}
+	if err == nil {
+		return nil
+	}
+	if q.writerSize == 0 {
+		// 分段里面还没有完整的记录，换新的分段会和它重名，只能返回错误
+		return err
+	}
+	return q.roll()
+}
+
+// Dequeue 按顺序取出下一个元素，以及它的偏移量，处理完之后需要调用 Ack
+// 没有可以取出的元素的时候返回 ErrEmptyQueue
+// 读到损坏的记录的时候返回 ErrCorruptRecord 和被跳过的最后一条记录的偏移量，调用方 Ack 这个偏移量就可以跳过：
+// 如果只是内容损坏，只跳过这一条记录；如果长度损坏，找不到下一条记录的位置，会跳过分段中剩下的所有记录
+func (q *PersistentQueue[T]) Dequeue() (T, uint64, error) {
+	var zero T
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return zero, 0, ErrQueueClosed
+	}
+	if q.readSeq >= q.writeSeq {
+		return zero, 0, ErrEmptyQueue
+	}
+	if err := q.advanceReader(); err != nil {
+		return zero, 0, err
+	}
+	payload, err := q.readRecord(q.reader)
+	if errors.Is(err, errChecksumMismatch) {
+		// 读取器已经越过了这条记录，直接往后走
+		offset := q.readSeq
+		q.readSeq++
+		return zero, offset, err
+	}
+	if err != nil {
+		if skipErr := q.skipSegment(); skipErr != nil {
+			return zero, 0, errors.Join(err, skipErr)
+		}
+		return zero, q.readSeq - 1, err
+	}
+	// 解码失败也要往后走，调用方可以 Ack 这个偏移量跳过这条记录
+	offset := q.readSeq
+	q.readSeq++
+	t, err := q.codec.Decode(payload)
+	return t, offset, err
+}
+
+// skipSegment 跳过正在读取的分段中剩下的记录，下一次 Dequeue 从下一个分段开始读
+// 正在读取的分段就是正在写入的分段时，先换一个新的分段，之后入队的元素才能被读到
+func (q *PersistentQueue[T]) skipSegment() error {
+	if q.readIdx+1 == len(q.segments) {
+		if err := q.roll(); err != nil {
+			return err
+		}
+	}
+	q.readSeq = q.segments[q.readIdx+1]
+	return nil
+}
+
+// Ack 确认偏移量小于等于 offset 的元素都已经处理完成
+// 只能确认已经出队的元素，否则返回 ErrInvalidOffset
+func (q *PersistentQueue[T]) Ack(offset uint64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if offset >= q.readSeq {
+		return ErrInvalidOffset
+	}
+	if offset < q.ackSeq {
+		return nil
+	}
+	q.ackSeq = offset + 1
+	if err := q.writeOffset(); err != nil {
+		return err
+	}
+	return q.compact()
+}
+
+// Len 返回还没有出队的元素个数
+func (q *PersistentQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return int(q.writeSeq - q.readSeq)
+}
+
+// Sync 把已经写入的数据刷到磁盘上
+func (q *PersistentQueue[T]) Sync() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	return q.writer.Sync()
+}
+
+// Close 关闭队列，已经出队但是没有 Ack 的元素在下一次打开的时候会重新出队
+func (q *PersistentQueue[T]) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	return q.closeFiles()
+}
+
+func (q *PersistentQueue[T]) closeFiles() error {
+	var err error
+	if q.writer != nil {
+		err = errors.Join(err, q.writer.Sync(), q.writer.Close())
+	}
+	if q.readFile != nil {
+		err = errors.Join(err, q.readFile.Close())
+	}
+	return err
+}
+
+// recover 从目录中恢复队列的状态
+func (q *PersistentQueue[T]) recover() error {
+	segments, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+	q.segments = segments
+	q.ackSeq, err = q.readOffset()
+	if err != nil {
+		return err
+	}
+	if len(q.segments) == 0 {
+		q.segments = []uint64{q.ackSeq}
+	}
+	// 只有最后一个分段可能在写入的时候崩溃，扫描一遍，截断末尾不完整的记录
+	// 中间校验失败的记录保留下来，交给 Dequeue 跳过
+	last := q.segments[len(q.segments)-1]
+	count, size, err := q.scanSegment(last)
+	if err != nil {
+		return err
+	}
+	q.writer, err = os.OpenFile(q.segmentPath(last), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err = q.writer.Truncate(size); err != nil {
+		return err
+	}
+	if _, err = q.writer.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	q.writerSize = size
+	q.writeSeq = last + count
+	q.ackSeq = max(min(q.ackSeq, q.writeSeq), q.segments[0])
+	q.readSeq = q.ackSeq
+
+	// 定位到 ackSeq 所在的分段，跳过已经 Ack 的记录
+	idx := sort.Search(len(q.segments), func(i int) bool {
+		return q.segments[i] > q.ackSeq
+	}) - 1
+	if err = q.openReader(idx); err != nil {
+		return err
+	}
+	for i := q.segments[idx]; i < q.ackSeq; i++ {
+		_, err = q.readRecord(q.reader)
+		if err == nil || errors.Is(err, errChecksumMismatch) {
+			continue
+		}
+		// 损坏的记录之后的位置已经被 Ack 了，只可能是 Dequeue 跳过了这个分段剩下的记录
+		// 最后一个分段在上面已经截断过了，所以这里一定还有下一个分段
+		if idx+1 == len(q.segments) {
+			return err
+		}
+		q.ackSeq = q.segments[idx+1]
+		q.readSeq = q.ackSeq
+		return q.openReader(idx + 1)
+	}
+	return nil
+}
+
+// scanSegment 返回分段中完整记录的个数，以及这些记录占用的字节数
+func (q *PersistentQueue[T]) scanSegment(base uint64) (uint64, int64, error) {
+	f, err := os.Open(q.segmentPath(base))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var count uint64
+	var size int64
+	// 校验失败但是长度完整的记录，只有后面还有完整的记录时才算进去
+	var pendingCount uint64
+	var pendingSize int64
+	for {
+		payload, err := q.readRecord(r)
+		if errors.Is(err, errChecksumMismatch) {
+			pendingCount++
+			pendingSize += int64(recordHeaderSize + len(payload))
+			continue
+		}
+		if err != nil {
+			// 读到末尾，或者末尾的记录不完整、校验失败，都从这里截断
+			return count, size, nil
+		}
+		count += pendingCount + 1
+		size += pendingSize + int64(recordHeaderSize+len(payload))
+		pendingCount, pendingSize = 0, 0
+	}
+}
+
+// readRecord 读取一条记录，返回记录的内容
+// 校验失败的时候同时返回读到的内容和 errChecksumMismatch
+func (q *PersistentQueue[T]) readRecord(r *bufio.Reader) ([]byte, error) {
+	header := q.headerBuf[:]
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptRecord, err)
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	if int64(length) > q.maxRecordSize() {
+		return nil, ErrCorruptRecord
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptRecord, err)
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return payload, errChecksumMismatch
+	}
+	return payload, nil
+}
+
+// maxRecordSize 单条记录的大小上限，避免读到损坏的长度之后分配过大的内存
+func (q *PersistentQueue[T]) maxRecordSize() int64 {
+	return max(q.segmentSize, defaultSegmentSize)
+}
+
+func (q *PersistentQueue[T]) openReader(idx int) error {
+	f, err := os.OpenFile(q.segmentPath(q.segments[idx]), os.O_CREATE|os.O_RDONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if q.readFile != nil {
+		_ = q.readFile.Close()
+	}
+	q.readFile = f
+	q.reader = bufio.NewReader(f)
+	q.readIdx = idx
+	return nil
+}
+
+// roll 当前分段写满了，创建一个新的分段
+func (q *PersistentQueue[T]) roll() error {
+	if err := q.writer.Close(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(q.segmentPath(q.writeSeq), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	q.writer = f
+	q.writerSize = 0
+	q.segments = append(q.segments, q.writeSeq)
+	return nil
+}
+
+// advanceReader 当前分段已经读完的时候，切换到下一个分段
+func (q *PersistentQueue[T]) advanceReader() error {
+	if q.readIdx+1 < len(q.segments) && q.segments[q.readIdx+1] == q.readSeq {
+		return q.openReader(q.readIdx + 1)
+	}
+	return nil
+}
+
+// compact 删除所有元素都已经 Ack 的分段，正在写入和正在读取的分段不会被删除
+// 有的系统（例如 Windows）不能删除打开着的文件，所以先把已经读完的分段关掉
+func (q *PersistentQueue[T]) compact() error {
+	if err := q.advanceReader(); err != nil {
+		return err
+	}
+	n := 0
+	for n < q.readIdx && q.segments[n+1] <= q.ackSeq {
+		if err := os.Remove(q.segmentPath(q.segments[n])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		n++
+	}
+	q.segments = q.segments[n:]
+	q.readIdx -= n
+	return nil
+}
+
+func (q *PersistentQueue[T]) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, base)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+	return res, nil
+}
+
+func (q *PersistentQueue[T]) readOffset() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(q.dir, offsetFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		if len(q.segments) > 0 {
+			return q.segments[0], nil
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// writeOffset 先写临时文件再重命名，保证 offset 文件不会只写了一半
+func (q *PersistentQueue[T]) writeOffset() error {
+	path := filepath.Join(q.dir, offsetFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(q.ackSeq, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (q *PersistentQueue[T]) segmentPath(base uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", base, segmentSuffix))
+}
@@ -0,0 +1,527 @@
+package queue
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var persistentCodecs = []struct {
+	name  string
+	codec Codec[codecUser]
+}{
+	{
+		name:  "json",
+		codec: JSONCodec[codecUser]{},
+	},
+	{
+		name:  "gob",
+		codec: GobCodec[codecUser]{},
+	},
+}
+
+func TestPersistentQueue_EnqueueDequeue(t *testing.T) {
+	for _, pc := range persistentCodecs {
+		t.Run(pc.name, func(t *testing.T) {
+			q, err := NewPersistentQueue[codecUser](t.TempDir(), 0, pc.codec)
+			require.NoError(t, err)
+			defer q.Close()
+
+			_, _, err = q.Dequeue()
+			assert.Equal(t, ErrEmptyQueue, err)
+
+			for i := 0; i < 10; i++ {
+				require.NoError(t, q.Enqueue(codecUser{Age: i}))
+			}
+			assert.Equal(t, 10, q.Len())
+			for i := 0; i < 10; i++ {
+				val, offset, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, codecUser{Age: i}, val)
+				assert.Equal(t, uint64(i), offset)
+			}
+			assert.Equal(t, 0, q.Len())
+			_, _, err = q.Dequeue()
+			assert.Equal(t, ErrEmptyQueue, err)
+
+			// 出队和入队交替进行
+			require.NoError(t, q.Enqueue(codecUser{Name: "Tom"}))
+			val, offset, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, codecUser{Name: "Tom"}, val)
+			assert.Equal(t, uint64(10), offset)
+		})
+	}
+}
+
+func TestPersistentQueue_Ack(t *testing.T) {
+	q, err := NewPersistentQueue[int](t.TempDir(), 0, JSONCodec[int]{})
+	require.NoError(t, err)
+	defer q.Close()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Enqueue(i))
+	}
+	// 还没有出队的元素不能 Ack
+	assert.Equal(t, ErrInvalidOffset, q.Ack(0))
+	_, _, err = q.Dequeue()
+	require.NoError(t, err)
+	_, _, err = q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, ErrInvalidOffset, q.Ack(2))
+	assert.NoError(t, q.Ack(1))
+	// 已经 Ack 过的偏移量再次 Ack 什么也不做
+	assert.NoError(t, q.Ack(0))
+
+	require.NoError(t, q.Close())
+	assert.NoError(t, q.Close())
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(3))
+	_, _, err = q.Dequeue()
+	assert.Equal(t, ErrQueueClosed, err)
+	assert.Equal(t, ErrQueueClosed, q.Ack(1))
+}
+
+// 分段写满之后创建新的分段，Ack 之后删除旧的分段
+func TestPersistentQueue_Segment(t *testing.T) {
+	for _, pc := range persistentCodecs {
+		t.Run(pc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := NewPersistentQueue[codecUser](dir, 256, pc.codec)
+			require.NoError(t, err)
+			defer q.Close()
+			const total = 100
+			for i := 0; i < total; i++ {
+				require.NoError(t, q.Enqueue(codecUser{Name: "Tom", Age: i}))
+			}
+			segments := countSegments(t, dir)
+			assert.Greater(t, segments, 5)
+
+			for i := 0; i < total/2; i++ {
+				val, offset, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, i, val.Age)
+				require.NoError(t, q.Ack(offset))
+			}
+			assert.Less(t, countSegments(t, dir), segments)
+
+			for i := total / 2; i < total; i++ {
+				val, offset, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, i, val.Age)
+				require.NoError(t, q.Ack(offset))
+			}
+			// 正在写入的分段不会被删除
+			assert.Equal(t, 1, countSegments(t, dir))
+
+			require.NoError(t, q.Enqueue(codecUser{Age: total}))
+			val, _, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, total, val.Age)
+		})
+	}
+}
+
+// Ack 落在分段边界上的时候，不会删除正在读取的分段
+func TestPersistentQueue_CompactReadingSegment(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewPersistentQueue[int](dir, 30, JSONCodec[int]{})
+	require.NoError(t, err)
+	defer q.Close()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, q.Enqueue(i))
+	}
+	for i := 0; i < 10; i++ {
+		val, offset, err := q.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, i, val)
+		require.NoError(t, q.Ack(offset))
+		require.GreaterOrEqual(t, q.readIdx, 0)
+		_, err = os.Stat(q.readFile.Name())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, countSegments(t, dir))
+}
+
+// 重启之后，没有 Ack 的元素会重新出队
+func TestPersistentQueue_Reopen(t *testing.T) {
+	for _, pc := range persistentCodecs {
+		t.Run(pc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := NewPersistentQueue[codecUser](dir, 128, pc.codec)
+			require.NoError(t, err)
+			for i := 0; i < 20; i++ {
+				require.NoError(t, q.Enqueue(codecUser{Age: i}))
+			}
+			for i := 0; i < 10; i++ {
+				_, _, err = q.Dequeue()
+				require.NoError(t, err)
+			}
+			require.NoError(t, q.Ack(6))
+			require.NoError(t, q.Close())
+
+			q, err = NewPersistentQueue[codecUser](dir, 128, pc.codec)
+			require.NoError(t, err)
+			defer q.Close()
+			assert.Equal(t, 13, q.Len())
+			for i := 7; i < 20; i++ {
+				val, offset, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, i, val.Age)
+				assert.Equal(t, uint64(i), offset)
+			}
+			_, _, err = q.Dequeue()
+			assert.Equal(t, ErrEmptyQueue, err)
+
+			// 重启之后继续写入，序号接着上一次的往后走
+			require.NoError(t, q.Enqueue(codecUser{Age: 20}))
+			val, offset, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, 20, val.Age)
+			assert.Equal(t, uint64(20), offset)
+		})
+	}
+}
+
+// 写了一半的记录在重启的时候被截断
+func TestPersistentQueue_TornWrite(t *testing.T) {
+	testCases := []struct {
+		name    string
+		damage  func(t *testing.T, path string)
+		wantRes []int
+	}{
+		{
+			name: "partial record",
+			damage: func(t *testing.T, path string) {
+				f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+				require.NoError(t, err)
+				defer f.Close()
+				_, err = f.Write([]byte{0, 0, 0, 10, 1, 2})
+				require.NoError(t, err)
+			},
+			wantRes: []int{0, 1, 2},
+		},
+		{
+			name: "checksum mismatch",
+			damage: func(t *testing.T, path string) {
+				data, err := os.ReadFile(path)
+				require.NoError(t, err)
+				data[len(data)-1]++
+				require.NoError(t, os.WriteFile(path, data, 0o644))
+			},
+			wantRes: []int{0, 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				require.NoError(t, q.Enqueue(i))
+			}
+			require.NoError(t, q.Close())
+
+			entries, err := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			tc.damage(t, entries[0])
+
+			q, err = NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			defer q.Close()
+			var res []int
+			for {
+				val, _, err := q.Dequeue()
+				if err != nil {
+					assert.Equal(t, ErrEmptyQueue, err)
+					break
+				}
+				res = append(res, val)
+			}
+			assert.Equal(t, tc.wantRes, res)
+
+			// 截断之后可以正常写入
+			require.NoError(t, q.Enqueue(100))
+			val, _, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, 100, val)
+		})
+	}
+}
+
+// 写入失败的时候撤销写了一半的记录，之后入队成功的元素重启之后都还在
+func TestPersistentQueue_PartialWrite(t *testing.T) {
+	testCases := []struct {
+		name string
+		// truncateErr 不为 nil 的时候截断也失败，只能换一个新的分段
+		truncateErr error
+	}{
+		{
+			name: "truncate",
+		},
+		{
+			name:        "roll",
+			truncateErr: errors.New("mock truncate error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			require.NoError(t, q.Enqueue(0))
+
+			w := &partialWriter{segmentWriter: q.writer, truncateErr: tc.truncateErr}
+			q.writer = w
+			w.fail = true
+			assert.ErrorIs(t, q.Enqueue(-1), errMockWrite)
+			w.fail = false
+			for i := 1; i < 5; i++ {
+				require.NoError(t, q.Enqueue(i))
+			}
+			assert.Equal(t, 5, q.Len())
+			for i := 0; i < 5; i++ {
+				val, _, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, i, val)
+			}
+			require.NoError(t, q.Close())
+
+			q, err = NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			defer q.Close()
+			res := make([]int, 0, 5)
+			for q.Len() > 0 {
+				val, _, err := q.Dequeue()
+				require.NoError(t, err)
+				res = append(res, val)
+			}
+			assert.Equal(t, []int{0, 1, 2, 3, 4}, res)
+		})
+	}
+}
+
+var errMockWrite = errors.New("mock write error")
+
+// partialWriter fail 为 true 的时候只写入一半的数据，然后返回错误
+type partialWriter struct {
+	segmentWriter
+	fail        bool
+	truncateErr error
+}
+
+func (w *partialWriter) Write(p []byte) (int, error) {
+	if !w.fail {
+		return w.segmentWriter.Write(p)
+	}
+	n, err := w.segmentWriter.Write(p[:len(p)/2])
+	if err != nil {
+		return n, err
+	}
+	return n, errMockWrite
+}
+
+func (w *partialWriter) Truncate(size int64) error {
+	if w.truncateErr != nil {
+		return w.truncateErr
+	}
+	return w.segmentWriter.Truncate(size)
+}
+
+// 分段中间的记录损坏，Dequeue 返回 ErrCorruptRecord 和可以 Ack 的偏移量，之后的记录可以继续出队
+func TestPersistentQueue_CorruptRecord(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 每条记录 9 个字节，每个分段 5 条记录，pos 是被修改的字节的位置
+		segment    int
+		pos        int
+		wantOffset uint64
+		wantRes    []int
+	}{
+		{
+			// 内容损坏，只跳过这一条
+			name:       "payload",
+			pos:        2*9 + 8,
+			wantOffset: 2,
+			wantRes:    []int{0, 1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+		},
+		{
+			// 长度损坏，跳过分段剩下的记录
+			name:       "length",
+			pos:        2 * 9,
+			wantOffset: 4,
+			wantRes:    []int{0, 1, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+		},
+		{
+			name:       "second segment",
+			segment:    1,
+			pos:        3 * 9,
+			wantOffset: 9,
+			wantRes:    []int{0, 1, 2, 3, 4, 5, 6, 7, 10, 11, 12, 13, 14},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := NewPersistentQueue[int](dir, 45, JSONCodec[int]{})
+			require.NoError(t, err)
+			for i := 0; i < 15; i++ {
+				require.NoError(t, q.Enqueue(i))
+			}
+			require.NoError(t, q.Close())
+			corruptByte(t, q.segmentPath(uint64(tc.segment*5)), tc.pos)
+
+			q, err = NewPersistentQueue[int](dir, 45, JSONCodec[int]{})
+			require.NoError(t, err)
+			res := make([]int, 0, 15)
+			for q.Len() > 0 {
+				val, offset, err := q.Dequeue()
+				if err != nil {
+					assert.ErrorIs(t, err, ErrCorruptRecord)
+					assert.Equal(t, tc.wantOffset, offset)
+				} else {
+					res = append(res, val)
+				}
+				require.NoError(t, q.Ack(offset))
+			}
+			assert.Equal(t, tc.wantRes, res)
+			require.NoError(t, q.Close())
+
+			// 全部 Ack 之后重新打开，不会再读到任何记录
+			q, err = NewPersistentQueue[int](dir, 45, JSONCodec[int]{})
+			require.NoError(t, err)
+			defer q.Close()
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+// 正在写入的分段中间的记录损坏，跳过之后新入队的元素依旧可以出队，重启之后也不会丢失
+func TestPersistentQueue_CorruptWritingSegment(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Enqueue(i))
+	}
+	// 还没有出队过，读取器里面没有缓存任何数据
+	corruptByte(t, q.segmentPath(0), 9)
+
+	val, offset, err := q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 0, val)
+	require.NoError(t, q.Ack(offset))
+	_, offset, err = q.Dequeue()
+	assert.ErrorIs(t, err, ErrCorruptRecord)
+	assert.Equal(t, uint64(2), offset)
+	_, _, err = q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	require.NoError(t, q.Enqueue(3))
+	require.NoError(t, q.Enqueue(4))
+	val, _, err = q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	require.NoError(t, q.Close())
+
+	// 没有 Ack 损坏的记录，重启之后会再次跳过
+	q, err = NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+	require.NoError(t, err)
+	defer q.Close()
+	_, offset, err = q.Dequeue()
+	assert.ErrorIs(t, err, ErrCorruptRecord)
+	assert.Equal(t, uint64(2), offset)
+	res := make([]int, 0, 2)
+	for q.Len() > 0 {
+		val, _, err = q.Dequeue()
+		require.NoError(t, err)
+		res = append(res, val)
+	}
+	assert.Equal(t, []int{3, 4}, res)
+}
+
+func TestPersistentQueue_CorruptLastSegment(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 每条记录 9 个字节，pos 是被修改的字节的位置
+		pos     int
+		wantLen int
+		wantRes []int
+		// 校验失败的记录的偏移量，-1 表示没有
+		wantOffset int
+	}{
+		{
+			// 中间的记录校验失败，后面完整的记录不能被截断
+			name:       "middle",
+			pos:        9 + 8,
+			wantLen:    5,
+			wantRes:    []int{0, 2, 3, 4},
+			wantOffset: 1,
+		},
+		{
+			// 最后一条记录校验失败，当作写入的时候崩溃，截断
+			name:       "last",
+			pos:        4*9 + 8,
+			wantLen:    4,
+			wantRes:    []int{0, 1, 2, 3},
+			wantOffset: -1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			for i := 0; i < 5; i++ {
+				require.NoError(t, q.Enqueue(i))
+			}
+			require.NoError(t, q.Close())
+			corruptByte(t, q.segmentPath(0), tc.pos)
+
+			q, err = NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			offset := -1
+			res := make([]int, 0, 5)
+			for q.Len() > 0 {
+				val, off, err := q.Dequeue()
+				if err != nil {
+					assert.ErrorIs(t, err, ErrCorruptRecord)
+					offset = int(off)
+				} else {
+					res = append(res, val)
+				}
+				require.NoError(t, q.Ack(off))
+			}
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantOffset, offset)
+
+			// 新写入的记录接在后面，重新打开之后还能读到
+			require.NoError(t, q.Enqueue(5))
+			require.NoError(t, q.Close())
+			q, err = NewPersistentQueue[int](dir, 0, JSONCodec[int]{})
+			require.NoError(t, err)
+			defer q.Close()
+			val, _, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, 5, val)
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+func corruptByte(t *testing.T, path string, pos int) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteAt([]byte{0xff}, int64(pos))
+	require.NoError(t, err)
+}
+
+func countSegments(t *testing.T, dir string) int {
+	entries, err := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	require.NoError(t, err)
+	return len(entries)
+}
@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"github.com/zmsocc/generic"
+	"github.com/zmsocc/generic/internal/queue"
+)
+
+// PriorityQueue 基于最小堆的优先级队列，不是并发安全的
+// 需要最大堆的时候，使用 generic.Reverse 反转比较器
+type PriorityQueue[T any] = queue.PriorityQueue[T]
+
+// PriorityQueueOption 优先级队列的可选配置
+type PriorityQueueOption[T any] = queue.Option[T]
+
+var _ Queue[any] = &PriorityQueue[any]{}
+
+// WithArity 指定堆的叉数，通常使用 2、4 或者 8，默认是二叉堆
+// 元素很多的时候，4 叉堆和 8 叉堆的吞吐量通常更高
+func WithArity[T any](arity int) PriorityQueueOption[T] {
+	return queue.WithArity[T](arity)
+}
+
+// NewPriorityQueue 创建一个优先级队列
+// capacity <= 0 表示无界队列
+func NewPriorityQueue[T any](capacity int, compare generic.Comparator[T], opts ...PriorityQueueOption[T]) *PriorityQueue[T] {
+	return queue.NewPriorityQueue[T](capacity, compare, opts...)
+}
+
+// NewPriorityQueueOf 用 src 构造优先级队列，时间复杂度为 O(n)
+func NewPriorityQueueOf[T any](src []T, capacity int, compare generic.Comparator[T], opts ...PriorityQueueOption[T]) (*PriorityQueue[T], error) {
+	return queue.NewPriorityQueueOf[T](src, capacity, compare, opts...)
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"sync"
+)
+
+// RingBuffer 固定容量的环形缓冲区，不是并发安全的
+// 和 ConcurrentArrayBlockingQueue 不同，写满之后不会阻塞，而是覆盖最旧的元素
+// 适合保存"最近 N 条"数据
+type RingBuffer[T any] struct {
+	data    []T
+	head    int    // 最旧元素下标
+	tail    int    // 下一次写入的下标
+	count   int    // 包含多少元素
+	written uint64 // 一共写入过多少个元素，用于计算读游标错过了多少元素
+	zero    T
+}
+
+// NewRingBuffer 创建一个环形缓冲区
+// capacity 必须为正数
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	return &RingBuffer[T]{
+		data: make([]T, capacity),
+	}
+}
+
+// Write 写入一个元素，写满之后会覆盖最旧的元素
+func (r *RingBuffer[T]) Write(t T) {
+	r.data[r.tail] = t
+	r.tail++
+	if r.tail == cap(r.data) {
+		r.tail = 0
+	}
+	if r.count == cap(r.data) { // 已经满了，最旧的元素被覆盖，head 跟着往后走
+		r.head = r.tail
+	} else {
+		r.count++
+	}
+	r.written++
+}
+
+// Snapshot 按照从旧到新的顺序返回所有元素的副本
+func (r *RingBuffer[T]) Snapshot() []T {
+	return r.Last(r.count)
+}
+
+// Last 按照从旧到新的顺序返回最新的 n 个元素的副本
+// n 超过元素个数的时候返回所有元素
+func (r *RingBuffer[T]) Last(n int) []T {
+	n = max(min(n, r.count), 0)
+	res := make([]T, n)
+	capacity := cap(r.data)
+	start := r.head + r.count - n
+	for i := 0; i < n; i++ {
+		res[i] = r.data[(start+i)%capacity]
+	}
+	return res
+}
+
+func (r *RingBuffer[T]) Len() int {
+	return r.count
+}
+
+func (r *RingBuffer[T]) Cap() int {
+	return cap(r.data)
+}
+
+// NewReader 创建一个读游标，从当前最旧的元素开始读
+func (r *RingBuffer[T]) NewReader() *RingBufferReader[T] {
+	return &RingBufferReader[T]{
+		rb:   r,
+		next: r.written - uint64(r.count),
+	}
+}
+
+// read 读取序号为 seq 的元素，调用方负责加锁
+// 返回值 next 为下一个要读取的序号，missed 为已经被覆盖、读不到的元素个数
+func (r *RingBuffer[T]) read(seq uint64) (val T, next uint64, missed int, err error) {
+	oldest := r.written - uint64(r.count)
+	if seq < oldest {
+		missed = int(oldest - seq)
+		seq = oldest
+	}
+	if seq >= r.written {
+		return r.zero, seq, missed, ErrEmptyQueue
+	}
+	idx := (r.head + int(seq-oldest)) % cap(r.data)
+	return r.data[idx], seq + 1, missed, nil
+}
+
+// RingBufferReader 环形缓冲区的读游标
+// 多个读游标之间互不影响，读取也不会删除元素
+// 读游标本身不是并发安全的，一个 goroutine 应该使用自己的读游标
+type RingBufferReader[T any] struct {
+	rb    *RingBuffer[T]
+	mutex *sync.RWMutex // 游标属于 ConcurrentRingBuffer 的时候不为 nil
+	next  uint64        // 下一个要读取的元素序号
+}
+
+// Read 读取下一个元素
+// missed 表示自上一次读取以来，因为被覆盖而没能读到的元素个数
+// 已经读到最新的元素的时候返回 ErrEmptyQueue
+func (r *RingBufferReader[T]) Read() (val T, missed int, err error) {
+	if r.mutex != nil {
+		r.mutex.RLock()
+		defer r.mutex.RUnlock()
+	}
+	val, r.next, missed, err = r.rb.read(r.next)
+	return val, missed, err
+}
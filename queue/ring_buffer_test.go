@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRingBuffer_Write(t *testing.T) {
+	testCases := []struct {
+		name      string
+		capacity  int
+		data      []int
+		wantSlice []int
+		wantLen   int
+		wantHead  int
+		wantTail  int
+	}{
+		{
+			name:      "empty",
+			capacity:  3,
+			data:      []int{},
+			wantSlice: []int{},
+		},
+		{
+			name:      "not full",
+			capacity:  3,
+			data:      []int{1, 2},
+			wantSlice: []int{1, 2},
+			wantLen:   2,
+			wantTail:  2,
+		},
+		{
+			name:      "just full",
+			capacity:  3,
+			data:      []int{1, 2, 3},
+			wantSlice: []int{1, 2, 3},
+			wantLen:   3,
+		},
+		{
+			// 覆盖最旧的元素
+			name:      "overwrite",
+			capacity:  3,
+			data:      []int{1, 2, 3, 4, 5},
+			wantSlice: []int{3, 4, 5},
+			wantLen:   3,
+			wantHead:  2,
+			wantTail:  2,
+		},
+		{
+			name:      "overwrite many times",
+			capacity:  3,
+			data:      []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			wantSlice: []int{8, 9, 10},
+			wantLen:   3,
+			wantHead:  1,
+			wantTail:  1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRingBuffer[int](tc.capacity)
+			for _, v := range tc.data {
+				r.Write(v)
+			}
+			assert.Equal(t, tc.wantSlice, r.Snapshot())
+			assert.Equal(t, tc.wantLen, r.Len())
+			assert.Equal(t, tc.capacity, r.Cap())
+			assert.Equal(t, tc.wantHead, r.head)
+			assert.Equal(t, tc.wantTail, r.tail)
+		})
+	}
+}
+
+func TestRingBuffer_Last(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []int
+		n       int
+		wantRes []int
+	}{
+		{
+			name:    "less than len",
+			data:    []int{1, 2, 3, 4, 5},
+			n:       2,
+			wantRes: []int{4, 5},
+		},
+		{
+			name:    "more than len",
+			data:    []int{1, 2},
+			n:       3,
+			wantRes: []int{1, 2},
+		},
+		{
+			name:    "negative",
+			data:    []int{1, 2},
+			n:       -1,
+			wantRes: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRingBuffer[int](3)
+			for _, v := range tc.data {
+				r.Write(v)
+			}
+			assert.Equal(t, tc.wantRes, r.Last(tc.n))
+		})
+	}
+}
+
+func TestRingBufferReader_Read(t *testing.T) {
+	r := NewRingBuffer[int](3)
+	reader := r.NewReader()
+	_, _, err := reader.Read()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	r.Write(1)
+	r.Write(2)
+	val, missed, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 0, missed)
+
+	// 写入 5 个元素，读游标停在 2 上，2、3 被覆盖
+	for i := 3; i <= 7; i++ {
+		r.Write(i)
+	}
+	val, missed, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 5, val)
+	assert.Equal(t, 3, missed)
+	val, missed, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 6, val)
+	assert.Equal(t, 0, missed)
+	val, _, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 7, val)
+	_, _, err = reader.Read()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	// 新的读游标从最旧的元素开始读，读取不会删除元素
+	another := r.NewReader()
+	val, missed, err = another.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 5, val)
+	assert.Equal(t, 0, missed)
+	assert.Equal(t, 3, r.Len())
+}
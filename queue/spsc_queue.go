@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+var _ Queue[any] = &SPSCQueue[any]{}
+
+const cacheLineSize = 64
+
+// cacheLinePad 填充一整个缓存行，避免生产者和消费者频繁修改的字段落在同一个缓存行上（伪共享）
+type cacheLinePad [cacheLineSize]byte
+
+// SPSCQueue 单生产者单消费者的有界无锁队列
+// 同一时刻只能有一个 goroutine 入队，一个 goroutine 出队，否则行为未定义
+// 队列满的时候入队返回 ErrOutOfCapacity，队列为空的时候出队返回 ErrEmptyQueue
+type SPSCQueue[T any] struct {
+	_    cacheLinePad
+	head atomic.Uint64 // 下一个出队的位置，只有消费者修改
+	_    cacheLinePad
+	tail atomic.Uint64 // 下一个入队的位置，只有生产者修改
+	_    cacheLinePad
+	mask uint64
+	data []T
+}
+
+// NewSPSCQueue 创建一个单生产者单消费者队列
+// capacity 必须为正数，并且会被向上取整为 2 的幂
+func NewSPSCQueue[T any](capacity int) *SPSCQueue[T] {
+	c := roundUpToPowerOfTwo(capacity)
+	return &SPSCQueue[T]{
+		mask: c - 1,
+		data: make([]T, c),
+	}
+}
+
+// Enqueue 入队，只能由唯一的生产者调用
+func (q *SPSCQueue[T]) Enqueue(t T) error {
+	tail := q.tail.Load()
+	if tail-q.head.Load() == uint64(len(q.data)) {
+		return ErrOutOfCapacity
+	}
+	q.data[tail&q.mask] = t
+	q.tail.Store(tail + 1) // 写完数据之后再发布，消费者看到新的 tail 时一定能看到数据
+	return nil
+}
+
+// Dequeue 出队，只能由唯一的消费者调用
+func (q *SPSCQueue[T]) Dequeue() (T, error) {
+	head := q.head.Load()
+	var zero T
+	if head == q.tail.Load() {
+		return zero, ErrEmptyQueue
+	}
+	idx := head & q.mask
+	res := q.data[idx]
+	q.data[idx] = zero // 为了释放内存，GC
+	q.head.Store(head + 1)
+	return res, nil
+}
+
+// Len 返回队列长度，在并发入队出队的时候只是一个近似值
+func (q *SPSCQueue[T]) Len() int {
+	head := q.head.Load()
+	return int(q.tail.Load() - head)
+}
+
+func (q *SPSCQueue[T]) Cap() int {
+	return len(q.data)
+}
+
+// roundUpToPowerOfTwo 将 n 向上取整为 2 的幂，n <= 1 的时候返回 1
+func roundUpToPowerOfTwo(n int) uint64 {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len64(uint64(n-1))
+}
@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"testing"
+)
+
+func TestNewSPSCQueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		wantCap  int
+	}{
+		{name: "zero", capacity: 0, wantCap: 1},
+		{name: "one", capacity: 1, wantCap: 1},
+		{name: "power of two", capacity: 8, wantCap: 8},
+		{name: "round up", capacity: 9, wantCap: 16},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewSPSCQueue[int](tc.capacity)
+			assert.Equal(t, tc.wantCap, q.Cap())
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+func TestSPSCQueue_EnqueueDequeue(t *testing.T) {
+	q := NewSPSCQueue[int](4)
+	_, err := q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+	// 多轮入队出队，下标会环绕
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			require.NoError(t, q.Enqueue(round*10+i))
+		}
+		assert.Equal(t, ErrOutOfCapacity, q.Enqueue(100))
+		assert.Equal(t, 4, q.Len())
+		for i := 0; i < 4; i++ {
+			val, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, round*10+i, val)
+		}
+		_, err = q.Dequeue()
+		assert.Equal(t, ErrEmptyQueue, err)
+	}
+}
+
+func TestSPSCQueue_Concurrent(t *testing.T) {
+	const total = 100000
+	q := NewSPSCQueue[int](64)
+	go func() {
+		for i := 0; i < total; {
+			if q.Enqueue(i) == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for i := 0; i < total; {
+		val, err := q.Dequeue()
+		if err == ErrEmptyQueue {
+			runtime.Gosched()
+			continue
+		}
+		require.Equal(t, i, val)
+		i++
+	}
+}
+
+// 一个生产者一个消费者，对比 SPSCQueue、ConcurrentArrayBlockingQueue 和 channel
+func BenchmarkSPSCQueue(b *testing.B) {
+	b.Run("SPSCQueue", func(b *testing.B) {
+		q := NewSPSCQueue[int](1024)
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < b.N; {
+				if _, err := q.Dequeue(); err == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+			close(done)
+		}()
+		for i := 0; i < b.N; {
+			if q.Enqueue(i) == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+		<-done
+	})
+	b.Run("ConcurrentArrayBlockingQueue", func(b *testing.B) {
+		q := NewConcurrentArrayBlockingQueue[int](1024)
+		ctx := context.Background()
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < b.N; i++ {
+				_, _ = q.Dequeue(ctx)
+			}
+			close(done)
+		}()
+		for i := 0; i < b.N; i++ {
+			_ = q.Enqueue(ctx, i)
+		}
+		<-done
+	})
+	b.Run("channel", func(b *testing.B) {
+		ch := make(chan int, 1024)
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < b.N; i++ {
+				<-ch
+			}
+			close(done)
+		}()
+		for i := 0; i < b.N; i++ {
+			ch <- i
+		}
+		<-done
+	})
+}
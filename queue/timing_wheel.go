@@ -0,0 +1,257 @@
+package queue
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间源，测试的时候可以替换成手动推进的实现
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker 对 time.Ticker 的抽象
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{t: time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r realTicker) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTicker) Stop() {
+	r.t.Stop()
+}
+
+// TimerHandle 调用 TimingWheel.Add 返回的句柄，用于取消定时任务
+type TimerHandle[T any] struct {
+	item       T
+	expiration int64 // 到期时间，单位是 tick，从时间轮创建开始计算
+	bucket     *timerBucket[T]
+	prev       *TimerHandle[T]
+	next       *TimerHandle[T]
+}
+
+// timerBucket 时间轮上的一个格子，内部是一个带哨兵的双向循环链表，删除任意结点都是 O(1) 的
+type timerBucket[T any] struct {
+	root TimerHandle[T]
+}
+
+func newTimerBucket[T any]() *timerBucket[T] {
+	b := &timerBucket[T]{}
+	b.root.prev = &b.root
+	b.root.next = &b.root
+	return b
+}
+
+func (b *timerBucket[T]) add(t *TimerHandle[T]) {
+	t.bucket = b
+	t.prev = b.root.prev
+	t.next = &b.root
+	t.prev.next = t
+	b.root.prev = t
+}
+
+func (b *timerBucket[T]) remove(t *TimerHandle[T]) {
+	t.prev.next = t.next
+	t.next.prev = t.prev
+	t.prev, t.next, t.bucket = nil, nil, nil
+}
+
+// flush 取出格子里的所有定时任务，并清空格子
+func (b *timerBucket[T]) flush() []*TimerHandle[T] {
+	var res []*TimerHandle[T]
+	for t := b.root.next; t != &b.root; {
+		next := t.next
+		t.prev, t.next, t.bucket = nil, nil, nil
+		res = append(res, t)
+		t = next
+	}
+	b.root.prev = &b.root
+	b.root.next = &b.root
+	return res
+}
+
+// wheel 一层时间轮
+type wheel[T any] struct {
+	unit        int64 // 一个格子代表多少个 tick
+	interval    int64 // 整层代表多少个 tick，等于 unit * len(buckets)
+	currentTime int64 // 当前时间，按照 unit 向下取整
+	buckets     []*timerBucket[T]
+}
+
+func newWheel[T any](unit int64, size int, currentTime int64) *wheel[T] {
+	buckets := make([]*timerBucket[T], size)
+	for i := range buckets {
+		buckets[i] = newTimerBucket[T]()
+	}
+	return &wheel[T]{
+		unit:        unit,
+		interval:    unit * int64(size),
+		currentTime: currentTime - currentTime%unit,
+		buckets:     buckets,
+	}
+}
+
+// TimingWheel 分层时间轮
+// 添加和取消定时任务都是 O(1) 的，适合管理大量的定时任务
+// 第一层每个格子代表一个 tick，超出第一层范围的定时任务会放到更高层的时间轮上，
+// 高层时间轮的格子到期之后，里面的定时任务会被重新放到低层的时间轮上
+type TimingWheel[T any] struct {
+	tick      time.Duration
+	wheelSize int
+	clock     Clock
+	start     time.Time
+	current   int64 // 已经推进了多少个 tick
+	wheels    []*wheel[T]
+	mutex     *sync.Mutex
+	c         chan T
+	stop      chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+// NewTimingWheel 创建一个分层时间轮并开始运转
+// tick 是时间轮的精度，wheelSize 是每层时间轮的格子数量，两者都必须为正数
+func NewTimingWheel[T any](tick time.Duration, wheelSize int) *TimingWheel[T] {
+	return NewTimingWheelWithClock[T](tick, wheelSize, realClock{})
+}
+
+// NewTimingWheelWithClock 使用指定的时间源创建一个分层时间轮并开始运转
+func NewTimingWheelWithClock[T any](tick time.Duration, wheelSize int, clock Clock) *TimingWheel[T] {
+	tw := &TimingWheel[T]{
+		tick:      tick,
+		wheelSize: wheelSize,
+		clock:     clock,
+		start:     clock.Now(),
+		wheels:    []*wheel[T]{newWheel[T](1, wheelSize, 0)},
+		mutex:     &sync.Mutex{},
+		c:         make(chan T, wheelSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go tw.run(clock.NewTicker(tick))
+	return tw
+}
+
+// Add 添加一个定时任务，delay 之后 item 会被发送到 C() 上
+// delay 会按照 tick 向上取整，小于等于 0 的时候在下一个 tick 到期
+func (tw *TimingWheel[T]) Add(delay time.Duration, item T) *TimerHandle[T] {
+	ticks := int64((delay + tw.tick - 1) / tw.tick)
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	t := &TimerHandle[T]{
+		item:       item,
+		expiration: tw.current + max(ticks, 1),
+	}
+	tw.add(t)
+	return t
+}
+
+// Cancel 取消定时任务，返回 false 表示定时任务已经到期或者已经被取消了
+func (tw *TimingWheel[T]) Cancel(h *TimerHandle[T]) bool {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if h == nil || h.bucket == nil {
+		return false
+	}
+	h.bucket.remove(h)
+	return true
+}
+
+// C 返回到期元素的 channel，时间轮停止之后会被关闭
+func (tw *TimingWheel[T]) C() <-chan T {
+	return tw.c
+}
+
+// Stop 停止时间轮，还没到期的定时任务不会再触发
+func (tw *TimingWheel[T]) Stop() {
+	tw.stopOnce.Do(func() {
+		close(tw.stop)
+	})
+	<-tw.done
+}
+
+func (tw *TimingWheel[T]) run(ticker Ticker) {
+	defer func() {
+		ticker.Stop()
+		close(tw.c)
+		close(tw.done)
+	}()
+	for {
+		select {
+		case <-tw.stop:
+			return
+		case now := <-ticker.C():
+			// ticker 可能会丢失 tick，所以按照实际经过的时间来推进
+			for _, item := range tw.advance(int64(now.Sub(tw.start) / tw.tick)) {
+				select {
+				case tw.c <- item:
+				case <-tw.stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+// advance 把时间轮推进到 target，返回这期间到期的元素
+func (tw *TimingWheel[T]) advance(target int64) []T {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	var expired []T
+	for tw.current < target {
+		tw.current++
+		for _, w := range tw.wheels {
+			w.currentTime = tw.current - tw.current%w.unit
+		}
+		for _, w := range tw.wheels {
+			if tw.current%w.unit != 0 {
+				continue
+			}
+			bucket := w.buckets[(tw.current/w.unit)%int64(len(w.buckets))]
+			for _, t := range bucket.flush() {
+				if t.expiration <= tw.current {
+					expired = append(expired, t.item)
+					continue
+				}
+				// 还没到期，重新放到更低层的时间轮上
+				tw.add(t)
+			}
+		}
+	}
+	return expired
+}
+
+// add 从最低层开始，找到能容纳该定时任务的时间轮
+// 调用方必须保证 t.expiration > tw.current
+func (tw *TimingWheel[T]) add(t *TimerHandle[T]) {
+	for i := 0; ; i++ {
+		if i == len(tw.wheels) {
+			// 超出所有时间轮的范围，创建更高一层的时间轮
+			upper := tw.wheels[i-1]
+			tw.wheels = append(tw.wheels, newWheel[T](upper.interval, tw.wheelSize, tw.current))
+		}
+		w := tw.wheels[i]
+		if t.expiration < w.currentTime+w.interval {
+			w.buckets[(t.expiration/w.unit)%int64(len(w.buckets))].add(t)
+			return
+		}
+	}
+}
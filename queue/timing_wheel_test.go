@@ -0,0 +1,218 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimingWheel_Advance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tick     time.Duration
+		size     int
+		delays   []time.Duration
+		advances []int64 // 依次推进到哪个 tick
+		want     [][]int // 每次推进之后到期的元素，元素就是 delays 的下标
+	}{
+		{
+			name:     "single wheel",
+			tick:     time.Millisecond,
+			size:     8,
+			delays:   []time.Duration{time.Millisecond * 3, time.Millisecond, time.Millisecond * 7},
+			advances: []int64{1, 2, 3, 7},
+			want:     [][]int{{1}, nil, {0}, {2}},
+		},
+		{
+			// delay 按照 tick 向上取整，小于等于 0 的在下一个 tick 到期
+			name:     "round up",
+			tick:     time.Millisecond * 10,
+			size:     8,
+			delays:   []time.Duration{time.Millisecond * 15, 0, -time.Second},
+			advances: []int64{1, 2},
+			want:     [][]int{{1, 2}, {0}},
+		},
+		{
+			// 超出第一层的范围，放到第二层、第三层
+			name:     "overflow",
+			tick:     time.Millisecond,
+			size:     4,
+			delays:   []time.Duration{time.Millisecond * 5, time.Millisecond * 17, time.Millisecond * 70},
+			advances: []int64{4, 5, 16, 17, 69, 70},
+			want:     [][]int{nil, {0}, nil, {1}, nil, {2}},
+		},
+		{
+			// 一次推进很多个 tick
+			name:     "advance many ticks",
+			tick:     time.Millisecond,
+			size:     4,
+			delays:   []time.Duration{time.Millisecond * 100, time.Millisecond * 3, time.Millisecond * 33},
+			advances: []int64{1000},
+			want:     [][]int{{1, 2, 0}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tw := newStoppedTimingWheel[int](tc.tick, tc.size)
+			for i, d := range tc.delays {
+				tw.Add(d, i)
+			}
+			for i, target := range tc.advances {
+				assert.Equal(t, tc.want[i], tw.advance(target), "推进到 %d", target)
+			}
+		})
+	}
+}
+
+func TestTimingWheel_Cancel(t *testing.T) {
+	tw := newStoppedTimingWheel[int](time.Millisecond, 4)
+	h1 := tw.Add(time.Millisecond*2, 1)
+	h2 := tw.Add(time.Millisecond*20, 2)
+	h3 := tw.Add(time.Millisecond*3, 3)
+	assert.True(t, tw.Cancel(h1))
+	assert.False(t, tw.Cancel(h1))
+	// 取消高层时间轮上的定时任务
+	assert.True(t, tw.Cancel(h2))
+	assert.False(t, tw.Cancel(nil))
+	assert.Equal(t, []int{3}, tw.advance(100))
+	// 已经到期的定时任务不能取消
+	assert.False(t, tw.Cancel(h3))
+
+	// 定时任务从高层降到低层之后依旧可以取消
+	h4 := tw.Add(time.Millisecond*10, 4)
+	assert.Nil(t, tw.advance(108))
+	assert.True(t, tw.Cancel(h4))
+	assert.Nil(t, tw.advance(200))
+}
+
+// 随机添加定时任务，每个定时任务都恰好在到期的那个 tick 被触发
+func TestTimingWheel_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tw := newStoppedTimingWheel[int64](time.Millisecond, 8)
+	const total = 2000
+	got := 0
+	step := func() {
+		target := tw.current + 1
+		expired := tw.advance(target)
+		for _, exp := range expired {
+			require.Equal(t, target, exp)
+		}
+		got += len(expired)
+	}
+	for i := 0; i < total; i++ {
+		// 在推进的过程中添加
+		for j := r.Intn(4); j > 0; j-- {
+			step()
+		}
+		ticks := int64(r.Intn(5000) + 1)
+		tw.Add(time.Duration(ticks)*time.Millisecond, tw.current+ticks)
+	}
+	for i := 0; i < 6000; i++ {
+		step()
+	}
+	assert.Equal(t, total, got)
+}
+
+// 使用手动推进的时间源驱动时间轮
+func TestTimingWheel_Clock(t *testing.T) {
+	clock := newFakeClock()
+	tw := NewTimingWheelWithClock[int](time.Millisecond*10, 4, clock)
+	defer tw.Stop()
+	tw.Add(time.Millisecond*30, 3)
+	tw.Add(time.Millisecond*10, 1)
+	tw.Add(time.Millisecond*200, 20)
+
+	clock.Advance(time.Millisecond * 10)
+	assert.Equal(t, 1, receive(t, tw.C()))
+	// ticker 丢失了一些 tick，按照实际经过的时间推进
+	clock.Advance(time.Millisecond * 50)
+	assert.Equal(t, 3, receive(t, tw.C()))
+	clock.Advance(time.Millisecond * 140)
+	assert.Equal(t, 20, receive(t, tw.C()))
+}
+
+func TestTimingWheel_Stop(t *testing.T) {
+	tw := NewTimingWheel[int](time.Millisecond, 8)
+	tw.Add(time.Millisecond*5, 1)
+	assert.Equal(t, 1, receive(t, tw.C()))
+	tw.Add(time.Hour, 2)
+	tw.Stop()
+	tw.Stop()
+	_, ok := <-tw.C()
+	assert.False(t, ok)
+}
+
+func BenchmarkTimingWheel_Add(b *testing.B) {
+	tw := newStoppedTimingWheel[int](time.Millisecond, 512)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tw.Add(time.Duration(i%100000)*time.Millisecond, i)
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	select {
+	case val := <-ch:
+		return val
+	case <-time.After(time.Second):
+		require.Fail(t, "超时没有收到到期元素")
+	}
+	var zero T
+	return zero
+}
+
+// newStoppedTimingWheel 创建一个不会自动推进的时间轮，测试的时候手动调用 advance
+func newStoppedTimingWheel[T any](tick time.Duration, size int) *TimingWheel[T] {
+	tw := NewTimingWheelWithClock[T](tick, size, newFakeClock())
+	tw.Stop()
+	return tw
+}
+
+type fakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) NewTicker(d time.Duration) Ticker {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	ticker := &fakeTicker{c: make(chan time.Time, 1)}
+	f.tickers = append(f.tickers, ticker)
+	return ticker
+}
+
+// Advance 推进时间，并且让所有的 ticker 触发一次
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mutex.Lock()
+	f.now = f.now.Add(d)
+	now := f.now
+	tickers := append([]*fakeTicker{}, f.tickers...)
+	f.mutex.Unlock()
+	for _, ticker := range tickers {
+		ticker.c <- now
+	}
+}
+
+type fakeTicker struct {
+	c chan time.Time
+}
+
+func (f *fakeTicker) C() <-chan time.Time {
+	return f.c
+}
+
+func (f *fakeTicker) Stop() {}
@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"github.com/zmsocc/generic"
+	"github.com/zmsocc/generic/internal/queue"
+	"golang.org/x/exp/slices"
+	"sync"
+)
+
+// TopK 从数据流中选出最靠前的 K 个元素，不是并发安全的
+// compare(a, b) < 0 表示 a 排在 b 前面，例如按照分数从高到低选择，compare 应当把分数高的排在前面
+// 内部是一个容量为 K 的堆，堆顶是目前保留的元素中排在最后的那个，
+// 满了之后新元素只需要和堆顶比较，每个元素的时间复杂度为 O(log K)
+type TopK[T any] struct {
+	k       int
+	compare generic.Comparator[T]
+	heap    *queue.PriorityQueue[T]
+}
+
+// NewTopK 创建一个 TopK 收集器，k 必须为正数
+func NewTopK[T any](k int, compare generic.Comparator[T]) *TopK[T] {
+	return &TopK[T]{
+		k:       k,
+		compare: compare,
+		// 反转比较器，让排在最后的元素位于堆顶
+		heap: queue.NewPriorityQueue[T](k, generic.Reverse(compare)),
+	}
+}
+
+// Offer 提交一个元素，返回该元素是否被保留
+// 已经保留了 K 个元素的时候，只有排在堆顶元素前面的新元素才会被保留，堆顶元素被淘汰
+func (t *TopK[T]) Offer(val T) bool {
+	if t.heap.Len() < t.k {
+		_ = t.heap.Enqueue(val)
+		return true
+	}
+	worst, err := t.heap.Peek()
+	if err != nil || t.compare(val, worst) >= 0 {
+		return false
+	}
+	_, _ = t.heap.ReplaceTop(val)
+	return true
+}
+
+// OfferAll 提交多个元素
+func (t *TopK[T]) OfferAll(vals ...T) {
+	for _, val := range vals {
+		t.Offer(val)
+	}
+}
+
+// Merge 把 other 保留的元素合并进来，用于并行地分段统计之后汇总，不会修改 other
+// 和自己合并不会有任何效果
+func (t *TopK[T]) Merge(other *TopK[T]) {
+	if other == t {
+		return
+	}
+	t.OfferAll(other.heap.AsSlice()...)
+}
+
+// Result 按照 compare 的顺序返回保留的元素，不会修改收集器
+func (t *TopK[T]) Result() []T {
+	res := t.heap.AsSlice()
+	slices.SortFunc(res, t.compare)
+	return res
+}
+
+// Len 返回保留的元素个数，不超过 K
+func (t *TopK[T]) Len() int {
+	return t.heap.Len()
+}
+
+// K 返回最多保留的元素个数
+func (t *TopK[T]) K() int {
+	return t.k
+}
+
+// ConcurrentTopK 并发安全的 TopK 收集器
+type ConcurrentTopK[T any] struct {
+	topK  *TopK[T]
+	mutex *sync.RWMutex
+}
+
+// NewConcurrentTopK 创建一个并发安全的 TopK 收集器，k 必须为正数
+func NewConcurrentTopK[T any](k int, compare generic.Comparator[T]) *ConcurrentTopK[T] {
+	return &ConcurrentTopK[T]{
+		topK:  NewTopK[T](k, compare),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// Offer 提交一个元素，返回该元素是否被保留
+func (c *ConcurrentTopK[T]) Offer(val T) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.topK.Offer(val)
+}
+
+// OfferAll 提交多个元素，只加一次锁
+func (c *ConcurrentTopK[T]) OfferAll(vals ...T) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.topK.OfferAll(vals...)
+}
+
+// Merge 把 other 保留的元素合并进来
+// 先复制 other 的元素再加自己的锁，两个收集器互相合并也不会死锁
+func (c *ConcurrentTopK[T]) Merge(other *ConcurrentTopK[T]) {
+	if other == c {
+		return
+	}
+	other.mutex.RLock()
+	vals := other.topK.heap.AsSlice()
+	other.mutex.RUnlock()
+	c.OfferAll(vals...)
+}
+
+// Result 按照 compare 的顺序返回保留的元素
+func (c *ConcurrentTopK[T]) Result() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.topK.Result()
+}
+
+func (c *ConcurrentTopK[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.topK.Len()
+}
+
+func (c *ConcurrentTopK[T]) K() int {
+	return c.topK.K()
+}
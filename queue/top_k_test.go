@@ -0,0 +1,144 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zmsocc/generic"
+	"golang.org/x/exp/slices"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestTopK_Offer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		k        int
+		compare  generic.Comparator[int]
+		vals     []int
+		wantKept []bool
+		wantRes  []int
+	}{
+		{
+			name:     "less than k",
+			k:        5,
+			compare:  generic.ComparatorOrdered[int],
+			vals:     []int{3, 1, 2},
+			wantKept: []bool{true, true, true},
+			wantRes:  []int{1, 2, 3},
+		},
+		{
+			// 保留最小的 3 个
+			name:     "smallest",
+			k:        3,
+			compare:  generic.ComparatorOrdered[int],
+			vals:     []int{5, 1, 4, 2, 6, 3},
+			wantKept: []bool{true, true, true, true, false, true},
+			wantRes:  []int{1, 2, 3},
+		},
+		{
+			// 保留最大的 3 个，和堆顶相等的元素不会被保留
+			name:     "largest",
+			k:        3,
+			compare:  generic.Reverse(generic.ComparatorOrdered[int]),
+			vals:     []int{5, 1, 4, 2, 6, 4},
+			wantKept: []bool{true, true, true, true, true, false},
+			wantRes:  []int{6, 5, 4},
+		},
+		{
+			name:     "empty",
+			k:        3,
+			compare:  generic.ComparatorOrdered[int],
+			vals:     []int{},
+			wantKept: []bool{},
+			wantRes:  []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topK := NewTopK[int](tc.k, tc.compare)
+			kept := make([]bool, 0, len(tc.vals))
+			for _, v := range tc.vals {
+				kept = append(kept, topK.Offer(v))
+			}
+			assert.Equal(t, tc.wantKept, kept)
+			assert.Equal(t, tc.wantRes, topK.Result())
+			assert.Equal(t, len(tc.wantRes), topK.Len())
+			assert.Equal(t, tc.k, topK.K())
+		})
+	}
+}
+
+// 分段统计之后合并，结果和整体统计一样
+func TestTopK_Merge(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	vals := make([]int, 10000)
+	for i := range vals {
+		vals[i] = r.Intn(100000)
+	}
+	compare := generic.Reverse(generic.ComparatorOrdered[int])
+	want := slices.Clone(vals)
+	slices.SortFunc(want, compare)
+	want = want[:100]
+
+	total := NewTopK[int](100, compare)
+	for i := 0; i < 4; i++ {
+		part := NewTopK[int](100, compare)
+		part.OfferAll(vals[i*2500 : (i+1)*2500]...)
+		total.Merge(part)
+		// 不会修改被合并的收集器
+		assert.Equal(t, 100, part.Len())
+	}
+	assert.Equal(t, want, total.Result())
+
+	// 和自己合并，结果不变
+	total.Merge(total)
+	assert.Equal(t, want, total.Result())
+}
+
+func TestConcurrentTopK(t *testing.T) {
+	compare := generic.ComparatorOrdered[int]
+	total := NewConcurrentTopK[int](10, compare)
+	parts := []*ConcurrentTopK[int]{
+		NewConcurrentTopK[int](10, compare),
+		NewConcurrentTopK[int](10, compare),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			for j := start; j < 8000; j += 8 {
+				total.Offer(j + 10000)
+				parts[j%2].OfferAll(j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	// 并发地合并到同一个收集器
+	for _, part := range parts {
+		wg.Add(1)
+		go func(part *ConcurrentTopK[int]) {
+			defer wg.Done()
+			total.Merge(part)
+		}(part)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, total.Result())
+	total.Merge(total)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, total.Result())
+	assert.Equal(t, 10, total.Len())
+	assert.Equal(t, 10, total.K())
+}
+
+func BenchmarkTopK_Offer(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	vals := make([]int, 1<<16)
+	for i := range vals {
+		vals[i] = r.Int()
+	}
+	topK := NewTopK[int](100, generic.Reverse(generic.ComparatorOrdered[int]))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topK.Offer(vals[i&(len(vals)-1)])
+	}
+}
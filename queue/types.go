@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/zmsocc/generic/internal/queue"
+)
+
+// Queue 非阻塞队列
+// 队列满的时候入队返回 ErrOutOfCapacity，队列为空的时候出队返回 ErrEmptyQueue
+// 出队顺序由具体的实现决定，例如普通队列是 FIFO，优先级队列按照优先级
+type Queue[T any] interface {
+	// Enqueue 入队
+	Enqueue(t T) error
+	// Dequeue 出队
+	Dequeue() (T, error)
+	// Len 返回队列中的元素个数，并发队列在并发入队出队的时候只是一个近似值
+	Len() int
+}
+
+// BlockingQueue 阻塞队列
+// 队列满的时候入队会阻塞，队列为空的时候出队会阻塞，直到条件满足或者 ctx 过期
+// ctx 过期的时候返回 ctx.Err()
+type BlockingQueue[T any] interface {
+	// Enqueue 入队
+	Enqueue(ctx context.Context, t T) error
+	// Dequeue 出队
+	Dequeue(ctx context.Context) (T, error)
+	// Len 返回队列中的元素个数
+	Len() int
+}
+
+// 以下类型的语义和 Queue、BlockingQueue 不同，因此没有实现这两个接口：
+//   - IndexedPriorityQueue：入队返回句柄
+//   - PersistentQueue：出队返回偏移量，需要 Ack
+//   - RingBuffer、ConcurrentRingBuffer：写满之后覆盖旧元素，读取不会删除元素
+//   - WorkStealingDeque：只有所有者可以入队
+//   - TimingWheel：元素到期之后从 channel 中取出
+
+var (
+	ErrQueueClosed   = errors.New("generic: 队列已关闭")
+	ErrOutOfCapacity = queue.ErrOutOfCapacity
+	ErrEmptyQueue    = queue.ErrEmptyQueue
+	ErrInvalidHandle = queue.ErrInvalidHandle
+	ErrInvalidOffset = errors.New("generic: 偏移量无效")
+	ErrCorruptRecord = errors.New("generic: 持久化队列的记录已损坏")
+)
@@ -0,0 +1,417 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmsocc/generic"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// queueSuite Queue 的一致性测试用例
+type queueSuite[T comparable] struct {
+	name string
+	// capacity 传给 newQueue 的容量，<= 0 表示无界队列，不测试容量
+	capacity int
+	newQueue func(capacity int) Queue[T]
+	// gen 生成第 i 个元素，按照 i 递增的顺序入队，出队的顺序也必须一样
+	gen func(i int) T
+	// concurrency 并发测试中生产者和消费者的数量，0 表示不是并发安全的，不做并发测试
+	concurrency int
+}
+
+// blockingQueueSuite BlockingQueue 的一致性测试用例
+type blockingQueueSuite[T comparable] struct {
+	name        string
+	capacity    int
+	newQueue    func(capacity int) BlockingQueue[T]
+	gen         func(i int) T
+	concurrency int
+}
+
+func TestQueueConformance(t *testing.T) {
+	intSuites := []queueSuite[int]{
+		{
+			name:     "PriorityQueue",
+			capacity: 8,
+			newQueue: func(capacity int) Queue[int] {
+				return NewPriorityQueue[int](capacity, generic.ComparatorOrdered[int])
+			},
+		},
+		{
+			name: "PriorityQueue unbounded",
+			newQueue: func(capacity int) Queue[int] {
+				return NewPriorityQueue[int](capacity, generic.ComparatorOrdered[int])
+			},
+		},
+		{
+			name:     "PriorityQueue 4-ary",
+			capacity: 64,
+			newQueue: func(capacity int) Queue[int] {
+				return NewPriorityQueue[int](capacity, generic.ComparatorOrdered[int], WithArity[int](4))
+			},
+		},
+		{
+			// 反转比较器得到最大堆，按照从大到小的顺序出队
+			name: "PriorityQueue max heap",
+			newQueue: func(capacity int) Queue[int] {
+				return NewPriorityQueue[int](capacity, generic.Reverse(generic.ComparatorOrdered[int]), WithArity[int](8))
+			},
+			gen: func(i int) int {
+				return -i
+			},
+		},
+		{
+			name:     "ConcurrentPriorityQueue",
+			capacity: 8,
+			newQueue: func(capacity int) Queue[int] {
+				return NewConcurrentPriorityQueue[int](capacity, generic.ComparatorOrdered[int])
+			},
+			concurrency: 4,
+		},
+		{
+			name: "ConcurrentLinkedQueue",
+			newQueue: func(int) Queue[int] {
+				return NewConcurrentLinkedQueue[int]()
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "MPMCQueue",
+			capacity: 8,
+			newQueue: func(capacity int) Queue[int] {
+				return NewMPMCQueue[int](capacity)
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "SPSCQueue",
+			capacity: 8,
+			newQueue: func(capacity int) Queue[int] {
+				return NewSPSCQueue[int](capacity)
+			},
+			concurrency: 1,
+		},
+		{
+			name: "Deque",
+			newQueue: func(capacity int) Queue[int] {
+				return NewDeque[int](capacity)
+			},
+		},
+	}
+	for _, s := range intSuites {
+		t.Run(s.name, func(t *testing.T) {
+			testQueue(t, s)
+		})
+	}
+}
+
+func TestBlockingQueueConformance(t *testing.T) {
+	intSuites := []blockingQueueSuite[int]{
+		{
+			name:     "ConcurrentArrayBlockingQueue",
+			capacity: 8,
+			newQueue: func(capacity int) BlockingQueue[int] {
+				return NewConcurrentArrayBlockingQueue[int](capacity)
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "ConcurrentLinkedBlockingQueue",
+			capacity: 8,
+			newQueue: func(capacity int) BlockingQueue[int] {
+				return NewConcurrentLinkedBlockingQueue[int](capacity)
+			},
+			concurrency: 4,
+		},
+		{
+			name: "ConcurrentLinkedBlockingQueue unbounded",
+			newQueue: func(capacity int) BlockingQueue[int] {
+				return NewConcurrentLinkedBlockingQueue[int](capacity)
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "ConcurrentBlockingPriorityQueue",
+			capacity: 8,
+			newQueue: func(capacity int) BlockingQueue[int] {
+				return NewConcurrentBlockingPriorityQueue[int](capacity, generic.ComparatorOrdered[int])
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "ConcurrentBlockingPriorityQueue 4-ary",
+			capacity: 32,
+			newQueue: func(capacity int) BlockingQueue[int] {
+				return NewConcurrentBlockingPriorityQueue[int](capacity, generic.ComparatorOrdered[int], WithArity[int](4))
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "ConcurrentBlockingDeque",
+			capacity: 8,
+			newQueue: func(capacity int) BlockingQueue[int] {
+				return NewConcurrentBlockingDeque[int](capacity)
+			},
+			concurrency: 4,
+		},
+		{
+			name:     "AgingPriorityQueue",
+			capacity: 8,
+			newQueue: func(capacity int) BlockingQueue[int] {
+				priority := func(t int) int64 { return int64(t) }
+				return NewAgingPriorityQueue[int](capacity, LinearAging(priority, time.Hour), 0)
+			},
+			concurrency: 4,
+		},
+		{
+			name: "DedupQueue",
+			newQueue: func(int) BlockingQueue[int] {
+				return NewDedupQueue[int, int](func(t int) int { return t }, nil)
+			},
+			concurrency: 4,
+		},
+	}
+	for _, s := range intSuites {
+		t.Run(s.name, func(t *testing.T) {
+			testBlockingQueue(t, s)
+		})
+	}
+
+	// 已经到期的元素，按照到期时间出队
+	base := time.Now().Add(-time.Hour)
+	delaySuite := blockingQueueSuite[delayItem]{
+		name:     "DelayQueue",
+		capacity: 8,
+		newQueue: func(capacity int) BlockingQueue[delayItem] {
+			return NewDelayQueue[delayItem](capacity)
+		},
+		gen: func(i int) delayItem {
+			return delayItem{val: i, deadline: base.Add(time.Duration(i) * time.Microsecond)}
+		},
+		concurrency: 4,
+	}
+	t.Run(delaySuite.name, func(t *testing.T) {
+		testBlockingQueue(t, delaySuite)
+	})
+}
+
+// testQueue 对 Queue 的实现运行一致性测试，新的实现只需要在 TestQueueConformance 中加一个用例
+func testQueue[T comparable](t *testing.T, s queueSuite[T]) {
+	gen := s.gen
+	if gen == nil {
+		gen = any(func(i int) int { return i }).(func(int) T)
+	}
+	n := s.capacity
+	if n <= 0 {
+		n = 100
+	}
+
+	t.Run("order", func(t *testing.T) {
+		q := s.newQueue(s.capacity)
+		_, err := q.Dequeue()
+		assert.Equal(t, ErrEmptyQueue, err)
+		for i := 0; i < n; i++ {
+			require.NoError(t, q.Enqueue(gen(i)))
+		}
+		assert.Equal(t, n, q.Len())
+		for i := 0; i < n; i++ {
+			val, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, gen(i), val)
+		}
+		assert.Equal(t, 0, q.Len())
+		_, err = q.Dequeue()
+		assert.Equal(t, ErrEmptyQueue, err)
+	})
+
+	if s.capacity > 0 {
+		t.Run("capacity", func(t *testing.T) {
+			q := s.newQueue(s.capacity)
+			for i := 0; i < s.capacity; i++ {
+				require.NoError(t, q.Enqueue(gen(i)))
+			}
+			assert.Equal(t, ErrOutOfCapacity, q.Enqueue(gen(s.capacity)))
+			assert.Equal(t, s.capacity, q.Len())
+			_, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.NoError(t, q.Enqueue(gen(s.capacity)))
+		})
+	}
+
+	if s.concurrency > 0 {
+		t.Run("concurrent", func(t *testing.T) {
+			q := s.newQueue(s.capacity)
+			runConcurrent(t, s.concurrency, gen, func(t T) error {
+				for {
+					err := q.Enqueue(t)
+					if err != ErrOutOfCapacity {
+						return err
+					}
+					runtime.Gosched()
+				}
+			}, func(stop *atomic.Bool) (T, bool) {
+				for !stop.Load() {
+					val, err := q.Dequeue()
+					if err == nil {
+						return val, true
+					}
+					runtime.Gosched()
+				}
+				var zero T
+				return zero, false
+			})
+		})
+	}
+}
+
+// testBlockingQueue 对 BlockingQueue 的实现运行一致性测试，新的实现只需要在 TestBlockingQueueConformance 中加一个用例
+func testBlockingQueue[T comparable](t *testing.T, s blockingQueueSuite[T]) {
+	gen := s.gen
+	if gen == nil {
+		gen = any(func(i int) int { return i }).(func(int) T)
+	}
+	n := s.capacity
+	if n <= 0 {
+		n = 100
+	}
+
+	t.Run("order", func(t *testing.T) {
+		q := s.newQueue(s.capacity)
+		for i := 0; i < n; i++ {
+			require.NoError(t, q.Enqueue(context.Background(), gen(i)))
+		}
+		assert.Equal(t, n, q.Len())
+		for i := 0; i < n; i++ {
+			val, err := q.Dequeue(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, gen(i), val)
+		}
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("dequeue timeout", func(t *testing.T) {
+		q := s.newQueue(s.capacity)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("dequeue wakeup", func(t *testing.T) {
+		q := s.newQueue(s.capacity)
+		res := make(chan T)
+		go func() {
+			val, err := q.Dequeue(context.Background())
+			assert.NoError(t, err)
+			res <- val
+		}()
+		time.Sleep(time.Millisecond * 5)
+		require.NoError(t, q.Enqueue(context.Background(), gen(0)))
+		assert.Equal(t, gen(0), receive(t, res))
+	})
+
+	if s.capacity > 0 {
+		t.Run("capacity", func(t *testing.T) {
+			q := s.newQueue(s.capacity)
+			for i := 0; i < s.capacity; i++ {
+				require.NoError(t, q.Enqueue(context.Background(), gen(i)))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+			defer cancel()
+			assert.Equal(t, context.DeadlineExceeded, q.Enqueue(ctx, gen(s.capacity)))
+			assert.Equal(t, s.capacity, q.Len())
+
+			// 队列满的时候入队阻塞，出队之后被唤醒
+			done := make(chan error)
+			go func() {
+				done <- q.Enqueue(context.Background(), gen(s.capacity))
+			}()
+			time.Sleep(time.Millisecond * 5)
+			_, err := q.Dequeue(context.Background())
+			require.NoError(t, err)
+			assert.NoError(t, receive(t, done))
+			assert.Equal(t, s.capacity, q.Len())
+		})
+	}
+
+	if s.concurrency > 0 {
+		t.Run("concurrent", func(t *testing.T) {
+			q := s.newQueue(s.capacity)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			runConcurrent(t, s.concurrency, gen, func(t T) error {
+				return q.Enqueue(context.Background(), t)
+			}, func(stop *atomic.Bool) (T, bool) {
+				// 生产者全部结束、元素全部取完之后，通过 cancel 让阻塞中的消费者退出
+				if stop.Load() {
+					cancel()
+				}
+				val, err := q.Dequeue(ctx)
+				return val, err == nil
+			})
+			cancel()
+		})
+	}
+}
+
+// runConcurrent 并发入队出队，校验每个元素都恰好出队一次
+// dequeue 返回 false 表示消费者应该退出
+func runConcurrent[T comparable](t *testing.T, concurrency int, gen func(i int) T,
+	enqueue func(t T) error, dequeue func(stop *atomic.Bool) (T, bool)) {
+	const perProducer = 500
+	total := concurrency * perProducer
+	var stop atomic.Bool
+	var received atomic.Int64
+	var mutex sync.Mutex
+	seen := make(map[T]int, total)
+
+	var producers, consumers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		producers.Add(1)
+		go func(start int) {
+			defer producers.Done()
+			for j := start; j < start+perProducer; j++ {
+				assert.NoError(t, enqueue(gen(j)))
+			}
+		}(i * perProducer)
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				val, ok := dequeue(&stop)
+				if !ok {
+					return
+				}
+				mutex.Lock()
+				seen[val]++
+				mutex.Unlock()
+				if received.Add(1) == int64(total) {
+					stop.Store(true)
+				}
+			}
+		}()
+	}
+	producers.Wait()
+	for received.Load() < int64(total) {
+		time.Sleep(time.Millisecond)
+	}
+	stop.Store(true)
+	// 唤醒可能阻塞在出队上的消费者
+	dequeue(&stop)
+	consumers.Wait()
+
+	assert.Len(t, seen, total)
+	for val, cnt := range seen {
+		assert.Equal(t, 1, cnt, "元素 %v", val)
+	}
+}
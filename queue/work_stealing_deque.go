@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+const defaultWorkStealingDequeCapacity = 32
+
+// wsRing WorkStealingDeque 底层的环形数组，容量是 2 的幂
+// 每个位置存放的是元素的指针，这样所有者写入和窃取者读取同一个位置的时候不会发生数据竞争
+type wsRing[T any] struct {
+	mask int64
+	buf  []atomic.Pointer[T]
+}
+
+func newWsRing[T any](capacity uint64) *wsRing[T] {
+	return &wsRing[T]{
+		mask: int64(capacity - 1),
+		buf:  make([]atomic.Pointer[T], capacity),
+	}
+}
+
+func (r *wsRing[T]) get(i int64) *T {
+	return r.buf[i&r.mask].Load()
+}
+
+func (r *wsRing[T]) put(i int64, t *T) {
+	r.buf[i&r.mask].Store(t)
+}
+
+// grow 扩容为原来的两倍，并把 [top, bottom) 之间的元素复制过去
+// 旧的数组不会被修改，正在读取旧数组的窃取者依旧能读到正确的元素
+func (r *wsRing[T]) grow(top, bottom int64) *wsRing[T] {
+	res := newWsRing[T](uint64(len(r.buf)) * 2)
+	for i := top; i < bottom; i++ {
+		res.put(i, r.get(i))
+	}
+	return res
+}
+
+// WorkStealingDeque 基于 Chase-Lev 算法实现的无锁工作窃取双端队列，容量没有上限
+// 只有所有者可以调用 PushBottom 和 PopBottom，在底部以 LIFO 的顺序存取元素；
+// 其他 goroutine（窃取者）可以并发调用 Steal，从顶部以 FIFO 的顺序取走元素
+// 所有者和窃取者只在剩下最后一个元素的时候才需要竞争
+type WorkStealingDeque[T any] struct {
+	_      cacheLinePad
+	top    atomic.Int64 // 下一个被窃取的位置，只会增加
+	_      cacheLinePad
+	bottom atomic.Int64 // 下一个 PushBottom 的位置，只有所有者修改
+	_      cacheLinePad
+	ring   atomic.Pointer[wsRing[T]]
+}
+
+// NewWorkStealingDeque 创建一个工作窃取双端队列
+// capacity 是初始容量，会被向上取整为 2 的幂，<= 0 的时候使用默认容量，写满之后自动扩容
+func NewWorkStealingDeque[T any](capacity int) *WorkStealingDeque[T] {
+	if capacity <= 0 {
+		capacity = defaultWorkStealingDequeCapacity
+	}
+	d := &WorkStealingDeque[T]{}
+	d.ring.Store(newWsRing[T](roundUpToPowerOfTwo(capacity)))
+	return d
+}
+
+// PushBottom 在底部放入元素，只能由所有者调用
+func (d *WorkStealingDeque[T]) PushBottom(t T) {
+	b := d.bottom.Load()
+	top := d.top.Load()
+	r := d.ring.Load()
+	if b-top >= int64(len(r.buf)) {
+		r = r.grow(top, b)
+		d.ring.Store(r)
+	}
+	r.put(b, &t)
+	// 写完元素之后再发布，窃取者看到新的 bottom 时一定能看到元素
+	d.bottom.Store(b + 1)
+}
+
+// PopBottom 从底部取出最后放入的元素，只能由所有者调用
+// 队列为空的时候返回 ErrEmptyQueue
+func (d *WorkStealingDeque[T]) PopBottom() (T, error) {
+	var zero T
+	b := d.bottom.Load() - 1
+	r := d.ring.Load()
+	// 先占住 b，再读取 top，之后开始窃取的窃取者都看不到 b 这个位置
+	d.bottom.Store(b)
+	top := d.top.Load()
+	if top > b {
+		d.bottom.Store(b + 1)
+		return zero, ErrEmptyQueue
+	}
+	p := r.get(b)
+	if top == b {
+		// 只剩最后一个元素，和窃取者竞争
+		won := d.top.CompareAndSwap(top, top+1)
+		d.bottom.Store(b + 1)
+		if !won {
+			return zero, ErrEmptyQueue
+		}
+	}
+	// 已经确定拿到了这个元素，窃取者不会再读取这个位置，可以清空，为了释放内存，GC
+	r.put(b, nil)
+	return *p, nil
+}
+
+// Steal 从顶部取走最早放入的元素，可以被任意 goroutine 并发调用
+// 队列为空的时候返回 ErrEmptyQueue
+func (d *WorkStealingDeque[T]) Steal() (T, error) {
+	for {
+		top := d.top.Load()
+		b := d.bottom.Load()
+		if top >= b {
+			var zero T
+			return zero, ErrEmptyQueue
+		}
+		// 必须在 CAS 之前读取，CAS 成功之后所有者可能会覆盖这个位置
+		// CAS 失败的时候读到的可能是 nil，所以只能在 CAS 成功之后解引用
+		// 窃取者也不能清空这个位置，因为所有者可能已经在这个位置上写入了新的元素
+		p := d.ring.Load().get(top)
+		if d.top.CompareAndSwap(top, top+1) {
+			return *p, nil
+		}
+		// 被别的窃取者或者所有者抢先了，重试
+	}
+}
+
+// Len 返回队列长度，在并发存取的时候只是一个近似值
+func (d *WorkStealingDeque[T]) Len() int {
+	b := d.bottom.Load()
+	top := d.top.Load()
+	return int(max(b-top, 0))
+}
@@ -0,0 +1,118 @@
+package queue
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWorkStealingDeque(t *testing.T) {
+	d := NewWorkStealingDeque[int](2)
+	_, err := d.PopBottom()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = d.Steal()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	// 超过初始容量，自动扩容
+	for i := 0; i < 10; i++ {
+		d.PushBottom(i)
+	}
+	assert.Equal(t, 10, d.Len())
+
+	// 所有者从底部 LIFO，窃取者从顶部 FIFO
+	val, err := d.PopBottom()
+	require.NoError(t, err)
+	assert.Equal(t, 9, val)
+	val, err = d.Steal()
+	require.NoError(t, err)
+	assert.Equal(t, 0, val)
+	val, err = d.Steal()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 7, d.Len())
+
+	var res []int
+	for {
+		val, err = d.PopBottom()
+		if err != nil {
+			assert.Equal(t, ErrEmptyQueue, err)
+			break
+		}
+		res = append(res, val)
+	}
+	assert.Equal(t, []int{8, 7, 6, 5, 4, 3, 2}, res)
+	assert.Equal(t, 0, d.Len())
+
+	// 取空之后还能继续使用
+	d.PushBottom(100)
+	val, err = d.Steal()
+	require.NoError(t, err)
+	assert.Equal(t, 100, val)
+	_, err = d.PopBottom()
+	assert.Equal(t, ErrEmptyQueue, err)
+}
+
+// 所有者不断放入和取出，多个窃取者并发窃取，每个元素恰好被取走一次
+func TestWorkStealingDeque_Concurrent(t *testing.T) {
+	const (
+		total   = 20000
+		thieves = 4
+	)
+	d := NewWorkStealingDeque[int](4)
+	seen := make([]atomic.Int32, total)
+	var taken atomic.Int64
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(thieves)
+	for i := 0; i < thieves; i++ {
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				val, err := d.Steal()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				seen[val].Add(1)
+				taken.Add(1)
+			}
+		}()
+	}
+	for i := 0; i < total; i++ {
+		d.PushBottom(i)
+		// 时不时从底部取出，制造和窃取者竞争最后一个元素的场景
+		if i%3 == 0 {
+			if val, err := d.PopBottom(); err == nil {
+				seen[val].Add(1)
+				taken.Add(1)
+			}
+		}
+	}
+	for {
+		val, err := d.PopBottom()
+		if err != nil {
+			break
+		}
+		seen[val].Add(1)
+		taken.Add(1)
+	}
+	for taken.Load() < total {
+		runtime.Gosched()
+	}
+	stop.Store(true)
+	wg.Wait()
+	for i := range seen {
+		require.Equal(t, int32(1), seen[i].Load(), "元素 %d", i)
+	}
+}
+
+func BenchmarkWorkStealingDeque_PushPop(b *testing.B) {
+	d := NewWorkStealingDeque[int](0)
+	for i := 0; i < b.N; i++ {
+		d.PushBottom(i)
+		_, _ = d.PopBottom()
+	}
+}
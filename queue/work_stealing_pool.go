@@ -0,0 +1,217 @@
+package queue
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// WorkStealingPool 基于工作窃取的任务池，适合递归地拆分任务的分治计算
+// 每个 Worker 都有自己的 WorkStealingDeque，任务执行过程中派生出来的子任务放到自己的队列里，
+// 按照 LIFO 的顺序执行，局部性更好；自己的队列空了之后，先从全局队列取任务，再从其他 Worker 那里窃取
+type WorkStealingPool struct {
+	workers []*Worker
+	global  *ConcurrentLinkedQueue[func(w *Worker)]
+	// wake 用于唤醒空闲的 Worker，容量等于 Worker 数量，所以发送的时候不需要阻塞
+	wake    chan struct{}
+	pending atomic.Int64 // 已经提交但是还没有执行完的任务数量
+
+	mutex  *sync.Mutex
+	idle   *cond // pending 降到 0 的时候 broadcast
+	closed bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Worker 任务池中的一个工作 goroutine，会作为参数传给任务，用于派生子任务
+type Worker struct {
+	id    int
+	pool  *WorkStealingPool
+	deque *WorkStealingDeque[func(w *Worker)]
+	rand  uint64
+}
+
+// NewWorkStealingPool 创建一个任务池并启动 workers 个 Worker
+// workers <= 0 的时候使用 runtime.GOMAXPROCS(0)
+func NewWorkStealingPool(workers int) *WorkStealingPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	m := &sync.Mutex{}
+	p := &WorkStealingPool{
+		workers: make([]*Worker, workers),
+		global:  NewConcurrentLinkedQueue[func(w *Worker)](),
+		wake:    make(chan struct{}, workers),
+		mutex:   m,
+		idle:    newCond(m),
+		stop:    make(chan struct{}),
+	}
+	for i := range p.workers {
+		p.workers[i] = &Worker{
+			id:    i,
+			pool:  p,
+			deque: NewWorkStealingDeque[func(w *Worker)](0),
+			rand:  uint64(i)*0x9E3779B97F4A7C15 + 1,
+		}
+	}
+	p.wg.Add(workers)
+	for _, w := range p.workers {
+		go w.run()
+	}
+	return p
+}
+
+// Submit 提交一个任务
+// 任务池关闭之后返回 ErrQueueClosed
+func (p *WorkStealingPool) Submit(task func()) error {
+	return p.Go(func(*Worker) {
+		task()
+	})
+}
+
+// Go 提交一个任务，任务可以通过参数 w 派生子任务
+// 任务池关闭之后返回 ErrQueueClosed
+func (p *WorkStealingPool) Go(task func(w *Worker)) error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	// 在锁里面增加计数，保证 Close 一定能等到这个任务执行完
+	p.pending.Add(1)
+	p.mutex.Unlock()
+	_ = p.global.Enqueue(task)
+	p.notify()
+	return nil
+}
+
+// Wait 等待所有已经提交的任务（包括派生出来的子任务）执行完
+func (p *WorkStealingPool) Wait(ctx context.Context) error {
+	for {
+		p.mutex.Lock()
+		if p.pending.Load() == 0 {
+			p.mutex.Unlock()
+			return nil
+		}
+		signal := p.idle.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// Close 关闭任务池，不再接收新的任务
+// 已经提交的任务依旧会执行完，执行完之后所有的 Worker 退出，Close 才返回
+// 不能在任务里面调用 Close，否则会死锁
+func (p *WorkStealingPool) Close() {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	p.mutex.Unlock()
+	_ = p.Wait(context.Background())
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// Workers 返回 Worker 的数量
+func (p *WorkStealingPool) Workers() int {
+	return len(p.workers)
+}
+
+// notify 唤醒一个空闲的 Worker，如果 wake 已经满了，说明所有的 Worker 都会被唤醒，直接丢弃即可
+func (p *WorkStealingPool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *WorkStealingPool) done() {
+	if p.pending.Add(-1) == 0 {
+		p.mutex.Lock()
+		p.idle.broadcast()
+	}
+}
+
+// ID 返回 Worker 的编号，范围是 [0, Workers())
+func (w *Worker) ID() int {
+	return w.id
+}
+
+// Spawn 派生一个子任务，子任务放在当前 Worker 自己的队列里
+// 只能在 w 正在执行的任务中调用。任务池关闭之后依旧可以派生子任务
+func (w *Worker) Spawn(task func(w *Worker)) {
+	w.pool.pending.Add(1)
+	w.deque.PushBottom(task)
+	w.pool.notify()
+}
+
+// RunUntil 在 done 返回 true 之前，执行别的任务，而不是阻塞等待
+// 用于在任务中等待自己派生的子任务执行完，只能在 w 正在执行的任务中调用
+func (w *Worker) RunUntil(done func() bool) {
+	for !done() {
+		if task, ok := w.next(); ok {
+			w.execute(task)
+			continue
+		}
+		runtime.Gosched()
+	}
+}
+
+func (w *Worker) run() {
+	defer w.pool.wg.Done()
+	for {
+		if task, ok := w.next(); ok {
+			w.execute(task)
+			continue
+		}
+		select {
+		case <-w.pool.stop:
+			return
+		case <-w.pool.wake:
+		}
+	}
+}
+
+func (w *Worker) execute(task func(w *Worker)) {
+	defer w.pool.done()
+	task(w)
+}
+
+// next 依次从自己的队列、全局队列、其他 Worker 的队列中获取任务
+func (w *Worker) next() (func(w *Worker), bool) {
+	if task, err := w.deque.PopBottom(); err == nil {
+		return task, true
+	}
+	if task, err := w.pool.global.Dequeue(); err == nil {
+		return task, true
+	}
+	// 从一个随机的 Worker 开始窃取，避免所有的窃取者都去抢同一个 Worker
+	workers := w.pool.workers
+	start := int(w.nextRand() % uint64(len(workers)))
+	for i := 0; i < len(workers); i++ {
+		victim := workers[(start+i)%len(workers)]
+		if victim == w {
+			continue
+		}
+		if task, err := victim.deque.Steal(); err == nil {
+			return task, true
+		}
+	}
+	return nil, false
+}
+
+// nextRand xorshift 伪随机数，每个 Worker 只在自己的 goroutine 里调用，不需要加锁
+func (w *Worker) nextRand() uint64 {
+	w.rand ^= w.rand << 13
+	w.rand ^= w.rand >> 7
+	w.rand ^= w.rand << 17
+	return w.rand
+}
@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkStealingPool_Submit(t *testing.T) {
+	p := NewWorkStealingPool(4)
+	defer p.Close()
+	assert.Equal(t, 4, p.Workers())
+	const total = 1000
+	var cnt atomic.Int64
+	for i := 0; i < total; i++ {
+		require.NoError(t, p.Submit(func() {
+			cnt.Add(1)
+		}))
+	}
+	require.NoError(t, p.Wait(context.Background()))
+	assert.Equal(t, int64(total), cnt.Load())
+}
+
+// 递归地拆分任务，子任务由各个 Worker 互相窃取执行
+func TestWorkStealingPool_Spawn(t *testing.T) {
+	p := NewWorkStealingPool(4)
+	defer p.Close()
+	data := make([]int64, 100000)
+	for i := range data {
+		data[i] = int64(i)
+	}
+	var sum atomic.Int64
+	var split func(w *Worker, lo, hi int)
+	split = func(w *Worker, lo, hi int) {
+		if hi-lo <= 100 {
+			var s int64
+			for _, v := range data[lo:hi] {
+				s += v
+			}
+			sum.Add(s)
+			return
+		}
+		mid := (lo + hi) / 2
+		w.Spawn(func(w *Worker) {
+			split(w, lo, mid)
+		})
+		w.Spawn(func(w *Worker) {
+			split(w, mid, hi)
+		})
+	}
+	require.NoError(t, p.Go(func(w *Worker) {
+		split(w, 0, len(data))
+	}))
+	require.NoError(t, p.Wait(context.Background()))
+	n := int64(len(data))
+	assert.Equal(t, n*(n-1)/2, sum.Load())
+}
+
+// 任务等待自己派生的子任务执行完，等待的过程中继续执行别的任务，单个 Worker 也不会死锁
+func TestWorkStealingPool_RunUntil(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		p := NewWorkStealingPool(workers)
+		var fib func(w *Worker, n int) int
+		fib = func(w *Worker, n int) int {
+			if n < 2 {
+				return n
+			}
+			var left int
+			var finished atomic.Bool
+			w.Spawn(func(w *Worker) {
+				left = fib(w, n-1)
+				finished.Store(true)
+			})
+			right := fib(w, n-2)
+			w.RunUntil(finished.Load)
+			return left + right
+		}
+		var res int
+		require.NoError(t, p.Go(func(w *Worker) {
+			res = fib(w, 20)
+		}))
+		require.NoError(t, p.Wait(context.Background()))
+		assert.Equal(t, 6765, res)
+		p.Close()
+	}
+}
+
+func TestWorkStealingPool_Close(t *testing.T) {
+	p := NewWorkStealingPool(2)
+	var cnt atomic.Int64
+	for i := 0; i < 100; i++ {
+		require.NoError(t, p.Submit(func() {
+			time.Sleep(time.Microsecond)
+			cnt.Add(1)
+		}))
+	}
+	// 关闭之前提交的任务都会执行完
+	p.Close()
+	assert.Equal(t, int64(100), cnt.Load())
+	assert.Equal(t, ErrQueueClosed, p.Submit(func() {}))
+	p.Close()
+}
+
+func TestWorkStealingPool_WaitTimeout(t *testing.T) {
+	p := NewWorkStealingPool(1)
+	release := make(chan struct{})
+	require.NoError(t, p.Submit(func() {
+		<-release
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, p.Wait(ctx))
+	close(release)
+	require.NoError(t, p.Wait(context.Background()))
+	p.Close()
+}
+
+func BenchmarkWorkStealingPool_Spawn(b *testing.B) {
+	p := NewWorkStealingPool(0)
+	defer p.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = p.Go(func(w *Worker) {
+			for j := 0; j < 64; j++ {
+				w.Spawn(func(*Worker) {})
+			}
+		})
+	}
+	_ = p.Wait(context.Background())
+}
@@ -14,3 +14,11 @@ func ComparatorOrdered[T Ordered](src T, dst T) int {
 	}
 	return 1
 }
+
+// Reverse 返回和 compare 顺序相反的比较器
+// 例如用 Reverse(ComparatorOrdered[int]) 构造优先级队列，得到的就是最大堆
+func Reverse[T any](compare Comparator[T]) Comparator[T] {
+	return func(src T, dst T) int {
+		return compare(dst, src)
+	}
+}
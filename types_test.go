@@ -0,0 +1,42 @@
+package generic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReverse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		src     int
+		dst     int
+		wantRes int
+	}{
+		{
+			name:    "less",
+			src:     1,
+			dst:     2,
+			wantRes: 1,
+		},
+		{
+			name:    "greater",
+			src:     2,
+			dst:     1,
+			wantRes: -1,
+		},
+		{
+			name:    "equal",
+			src:     1,
+			dst:     1,
+			wantRes: 0,
+		},
+	}
+	reverse := Reverse(ComparatorOrdered[int])
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantRes, reverse(tc.src, tc.dst))
+			// 反转两次等于没有反转
+			assert.Equal(t, ComparatorOrdered(tc.src, tc.dst), Reverse(reverse)(tc.src, tc.dst))
+		})
+	}
+}